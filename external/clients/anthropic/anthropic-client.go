@@ -0,0 +1,176 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/pavitra93/11-openai-chats/external/clients/llm"
+)
+
+const (
+	defaultBaseURL = "https://api.anthropic.com"
+	apiVersion     = "2023-06-01"
+)
+
+// Provider is the llm.Provider of the Anthropic Messages API.
+type Provider struct {
+	APIKey     string
+	BaseURL    string            // defaults to https://api.anthropic.com
+	Headers    map[string]string // extra headers sent with every request
+	HTTPClient *http.Client
+
+	NativeTools bool // false for compatible servers without tool use
+}
+
+// NewProvider creates a Provider for the given API key.
+func NewProvider(apiKey string) *Provider {
+	return &Provider{
+		APIKey:      apiKey,
+		BaseURL:     defaultBaseURL,
+		HTTPClient:  &http.Client{},
+		NativeTools: true,
+	}
+}
+
+// NewProviderFromProfile creates a Provider from an anthropic profile.
+func NewProviderFromProfile(profile *llm.ProviderProfile) *Provider {
+	p := NewProvider(profile.APIKey)
+	if profile.BaseURL != "" {
+		p.BaseURL = profile.BaseURL
+	}
+	p.Headers = profile.Headers
+	p.NativeTools = profile.SupportsNativeTools()
+	return p
+}
+
+func (p *Provider) Name() string { return "anthropic" }
+
+func (p *Provider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{NativeTools: p.NativeTools}
+}
+
+func (p *Provider) Complete(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	req, err := toMessagesRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.do(ctx, http.MethodPost, "/v1/messages", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var msg messagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("anthropic: failed to decode response: %w", err)
+	}
+	return toChatCompletion(&msg)
+}
+
+func (p *Provider) Stream(ctx context.Context, params openai.ChatCompletionNewParams) llm.ChunkStream {
+	req, err := toMessagesRequest(params)
+	if err != nil {
+		return &chunkStream{err: err}
+	}
+	req.Stream = true
+	resp, err := p.do(ctx, http.MethodPost, "/v1/messages", req)
+	if err != nil {
+		return &chunkStream{err: err}
+	}
+	return newChunkStream(resp.Body)
+}
+
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	out := make([]string, 0)
+	after := ""
+	for {
+		path := "/v1/models?limit=1000"
+		if after != "" {
+			path += "&after_id=" + url.QueryEscape(after)
+		}
+		resp, err := p.do(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: failed to decode models: %w", err)
+		}
+		for _, m := range page.Data {
+			out = append(out, m.ID)
+		}
+		if !page.HasMore || page.LastID == "" {
+			return out, nil
+		}
+		after = page.LastID
+	}
+}
+
+// do sends a request to the API and turns non-2xx responses into errors.
+func (p *Provider) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.BaseURL, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+	if p.APIKey != "" {
+		req.Header.Set("x-api-key", p.APIKey)
+	}
+	req.Header.Set("anthropic-version", apiVersion)
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+
+	client := p.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var apiErr errorResponse
+		if json.Unmarshal(b, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("anthropic: %s: %s (status %d)", apiErr.Error.Type, apiErr.Error.Message, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("anthropic: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return resp, nil
+}
+
+// errorResponse is the body of failed requests and of "error" stream events.
+type errorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
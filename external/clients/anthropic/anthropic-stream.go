@@ -0,0 +1,166 @@
+package anthropic
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// streamEvent is one server-sent event of a streamed Messages API response.
+type streamEvent struct {
+	Type         string           `json:"type"`
+	Index        int              `json:"index"`
+	Message      messagesResponse `json:"message"`       // message_start
+	ContentBlock contentBlock     `json:"content_block"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"` // text_delta, input_json_delta
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"` // message_delta
+	} `json:"delta"`
+	Usage usage `json:"usage"` // message_delta
+	errorResponse
+}
+
+// chunkStream translates the Messages API event stream into chat completion chunks.
+type chunkStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	pending []openai.ChatCompletionChunk
+	current openai.ChatCompletionChunk
+	err     error
+
+	id, model string
+	created   int64
+	usage     usage
+	toolCalls map[int]int // content block index -> tool call index
+}
+
+func newChunkStream(body io.ReadCloser) *chunkStream {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	return &chunkStream{
+		body:      body,
+		scanner:   scanner,
+		created:   time.Now().Unix(),
+		toolCalls: make(map[int]int),
+	}
+}
+
+func (s *chunkStream) Next() bool {
+	for len(s.pending) == 0 {
+		if s.err != nil || s.scanner == nil || !s.scanner.Scan() {
+			if s.err == nil && s.scanner != nil {
+				s.err = s.scanner.Err()
+			}
+			return false
+		}
+		data, ok := strings.CutPrefix(s.scanner.Text(), "data:")
+		if !ok {
+			continue // event names repeat the "type" of the data; comments and blank lines carry nothing
+		}
+		var ev streamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &ev); err != nil {
+			s.err = fmt.Errorf("anthropic: failed to decode stream event: %w", err)
+			return false
+		}
+		s.handle(&ev)
+	}
+	s.current, s.pending = s.pending[0], s.pending[1:]
+	return true
+}
+
+func (s *chunkStream) Current() openai.ChatCompletionChunk { return s.current }
+
+func (s *chunkStream) Err() error { return s.err }
+
+func (s *chunkStream) Close() error {
+	if s.body == nil {
+		return nil
+	}
+	return s.body.Close()
+}
+
+// handle queues the chunks an event translates to.
+func (s *chunkStream) handle(ev *streamEvent) {
+	switch ev.Type {
+	case "message_start":
+		s.id, s.model = ev.Message.ID, ev.Message.Model
+		s.usage = ev.Message.Usage
+		s.emit(map[string]any{"role": "assistant"}, "", nil)
+	case "content_block_start":
+		if ev.ContentBlock.Type == "tool_use" {
+			index := len(s.toolCalls)
+			s.toolCalls[ev.Index] = index
+			s.emit(map[string]any{"tool_calls": []any{map[string]any{
+				"index":    index,
+				"id":       ev.ContentBlock.ID,
+				"type":     "function",
+				"function": map[string]any{"name": ev.ContentBlock.Name, "arguments": ""},
+			}}}, "", nil)
+		}
+	case "content_block_delta":
+		switch ev.Delta.Type {
+		case "text_delta":
+			s.emit(map[string]any{"content": ev.Delta.Text}, "", nil)
+		case "input_json_delta":
+			if index, ok := s.toolCalls[ev.Index]; ok && ev.Delta.PartialJSON != "" {
+				s.emit(map[string]any{"tool_calls": []any{map[string]any{
+					"index":    index,
+					"function": map[string]any{"arguments": ev.Delta.PartialJSON},
+				}}}, "", nil)
+			}
+		}
+	case "message_delta":
+		// output tokens are cumulative; input counts are only repeated by some API versions
+		s.usage.OutputTokens = ev.Usage.OutputTokens
+		if ev.Usage.InputTokens > 0 {
+			s.usage.InputTokens = ev.Usage.InputTokens
+		}
+		s.emit(map[string]any{}, finishReason(ev.Delta.StopReason), nil)
+		usage := chatUsage(s.usage)
+		s.emit(nil, "", usage)
+	case "error":
+		s.err = errors.New("anthropic: stream error: " + ev.Error.Type + ": " + ev.Error.Message)
+	}
+}
+
+// emit queues a chunk with one choice delta (delta != nil) or a usage-only chunk.
+// Chunks are built through JSON so the accumulator sees which delta fields are set.
+func (s *chunkStream) emit(delta map[string]any, finish string, usage map[string]any) {
+	raw := map[string]any{
+		"id":      s.id,
+		"object":  "chat.completion.chunk",
+		"created": s.created,
+		"model":   s.model,
+		"choices": []any{},
+	}
+	if delta != nil {
+		choice := map[string]any{"index": 0, "delta": delta}
+		if finish != "" {
+			choice["finish_reason"] = finish
+		}
+		raw["choices"] = []any{choice}
+	}
+	if usage != nil {
+		raw["usage"] = usage
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		s.err = err
+		return
+	}
+	var chunk openai.ChatCompletionChunk
+	if err := json.Unmarshal(b, &chunk); err != nil {
+		s.err = fmt.Errorf("anthropic: failed to build chunk: %w", err)
+		return
+	}
+	s.pending = append(s.pending, chunk)
+}
@@ -0,0 +1,396 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+const (
+	// defaultMaxTokens is sent when the request sets no limit; the Messages API requires one
+	defaultMaxTokens = 4096
+	// maxTemperature is the upper bound of the Messages API (OpenAI allows up to 2)
+	maxTemperature = 1.0
+)
+
+// Chat completion request as sent on the wire; the SDK params are translated via their JSON form.
+type chatRequest struct {
+	Model               string          `json:"model"`
+	Messages            []chatMessage   `json:"messages"`
+	MaxTokens           int64           `json:"max_tokens"`
+	MaxCompletionTokens int64           `json:"max_completion_tokens"`
+	Temperature         *float64        `json:"temperature"`
+	TopP                *float64        `json:"top_p"`
+	Stop                json.RawMessage `json:"stop"`
+	Tools               []chatTool      `json:"tools"`
+	ToolChoice          json.RawMessage `json:"tool_choice"`
+	ParallelToolCalls   *bool           `json:"parallel_tool_calls"`
+}
+
+type chatMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"` // string or content parts
+	ToolCalls  []chatToolCall  `json:"tool_calls"`
+	ToolCallID string          `json:"tool_call_id"`
+}
+
+type chatContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+// Messages API request and response.
+type messagesRequest struct {
+	Model         string      `json:"model"`
+	System        string      `json:"system,omitempty"`
+	Messages      []message   `json:"messages"`
+	MaxTokens     int64       `json:"max_tokens"`
+	Temperature   *float64    `json:"temperature,omitempty"`
+	TopP          *float64    `json:"top_p,omitempty"`
+	StopSequences []string    `json:"stop_sequences,omitempty"`
+	Tools         []tool      `json:"tools,omitempty"`
+	ToolChoice    *toolChoice `json:"tool_choice,omitempty"`
+	Stream        bool        `json:"stream,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"` // "user" or "assistant"
+	Content []contentBlock `json:"content"`
+}
+
+type contentBlock struct {
+	Type      string          `json:"type"` // text, image, tool_use, tool_result
+	Text      string          `json:"text,omitempty"`
+	Source    *imageSource    `json:"source,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type imageSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type                   string `json:"type"` // auto, any, tool, none
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+type messagesResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+type usage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+}
+
+// toMessagesRequest translates chat completion params: system and developer messages become the
+// system prompt, tool calls become tool_use blocks and tool messages become tool_result blocks
+// of a user message. Consecutive messages of the same role are merged, as the API requires.
+func toMessagesRequest(params openai.ChatCompletionNewParams) (*messagesRequest, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: failed to encode params: %w", err)
+	}
+	var in chatRequest
+	if err := json.Unmarshal(b, &in); err != nil {
+		return nil, fmt.Errorf("anthropic: failed to decode params: %w", err)
+	}
+
+	out := &messagesRequest{
+		Model:       in.Model,
+		MaxTokens:   max(in.MaxCompletionTokens, in.MaxTokens),
+		Temperature: in.Temperature,
+		TopP:        in.TopP,
+	}
+	if out.MaxTokens <= 0 {
+		out.MaxTokens = defaultMaxTokens
+	}
+	if out.Temperature != nil && *out.Temperature > maxTemperature {
+		t := maxTemperature
+		out.Temperature = &t
+	}
+	out.StopSequences = stopSequences(in.Stop)
+
+	var system []string
+	for _, m := range in.Messages {
+		switch m.Role {
+		case "system", "developer":
+			if text := contentText(m.Content); text != "" {
+				system = append(system, text)
+			}
+		case "user":
+			out.appendBlocks("user", userBlocks(m.Content)...)
+		case "assistant":
+			blocks := make([]contentBlock, 0, 1+len(m.ToolCalls))
+			if text := contentText(m.Content); text != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: text})
+			}
+			for _, tc := range m.ToolCalls {
+				blocks = append(blocks, contentBlock{Type: "tool_use", ID: tc.ID, Name: tc.Function.Name, Input: toolInput(tc.Function.Arguments)})
+			}
+			out.appendBlocks("assistant", blocks...)
+		case "tool":
+			out.appendBlocks("user", contentBlock{Type: "tool_result", ToolUseID: m.ToolCallID, Content: contentText(m.Content)})
+		default:
+			slog.Warn("anthropic: skipping message with unsupported role", "role", m.Role)
+		}
+	}
+	out.System = strings.Join(system, "\n\n")
+
+	for _, t := range in.Tools {
+		if t.Type != "function" {
+			continue
+		}
+		schema := t.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		out.Tools = append(out.Tools, tool{Name: t.Function.Name, Description: t.Function.Description, InputSchema: schema})
+	}
+	if len(out.Tools) > 0 {
+		out.ToolChoice = translateToolChoice(in.ToolChoice)
+		if in.ParallelToolCalls != nil && !*in.ParallelToolCalls {
+			if out.ToolChoice == nil {
+				out.ToolChoice = &toolChoice{Type: "auto"}
+			}
+			// "none" takes no options
+			out.ToolChoice.DisableParallelToolUse = out.ToolChoice.Type != "none"
+		}
+	}
+	return out, nil
+}
+
+// appendBlocks adds blocks to the conversation, merging them into the last message of the same role.
+func (r *messagesRequest) appendBlocks(role string, blocks ...contentBlock) {
+	if len(blocks) == 0 {
+		return
+	}
+	if n := len(r.Messages); n > 0 && r.Messages[n-1].Role == role {
+		r.Messages[n-1].Content = append(r.Messages[n-1].Content, blocks...)
+		return
+	}
+	r.Messages = append(r.Messages, message{Role: role, Content: blocks})
+}
+
+// contentText flattens string or text-part content.
+func contentText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var parts []chatContentPart
+	if json.Unmarshal(raw, &parts) != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// userBlocks translates user content, including image parts.
+func userBlocks(raw json.RawMessage) []contentBlock {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		if s == "" {
+			return nil
+		}
+		return []contentBlock{{Type: "text", Text: s}}
+	}
+	var parts []chatContentPart
+	if json.Unmarshal(raw, &parts) != nil {
+		return nil
+	}
+	blocks := make([]contentBlock, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			if p.Text != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: p.Text})
+			}
+		case "image_url":
+			if src := imageFromURL(p.ImageURL.URL); src != nil {
+				blocks = append(blocks, contentBlock{Type: "image", Source: src})
+			}
+		default:
+			slog.Warn("anthropic: skipping unsupported content part", "type", p.Type)
+		}
+	}
+	return blocks
+}
+
+// imageFromURL turns a data: URL into a base64 source and anything else into a URL source.
+func imageFromURL(u string) *imageSource {
+	rest, ok := strings.CutPrefix(u, "data:")
+	if !ok {
+		return &imageSource{Type: "url", URL: u}
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	mediaType, isBase64 := strings.CutSuffix(meta, ";base64")
+	if !ok || !isBase64 {
+		slog.Warn("anthropic: skipping image data URL that is not base64")
+		return nil
+	}
+	return &imageSource{Type: "base64", MediaType: mediaType, Data: data}
+}
+
+// toolInput returns tool call arguments as a JSON object; the API rejects anything else.
+func toolInput(arguments string) json.RawMessage {
+	var obj map[string]any
+	if json.Unmarshal([]byte(arguments), &obj) != nil || obj == nil {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
+
+func stopSequences(raw json.RawMessage) []string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil && s != "" {
+		return []string{s}
+	}
+	var list []string
+	_ = json.Unmarshal(raw, &list)
+	return list
+}
+
+// translateToolChoice maps "auto", "none", "required" and named functions; nil keeps the API default.
+func translateToolChoice(raw json.RawMessage) *toolChoice {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		switch s {
+		case "auto":
+			return &toolChoice{Type: "auto"}
+		case "none":
+			return &toolChoice{Type: "none"}
+		case "required":
+			return &toolChoice{Type: "any"}
+		}
+		return nil
+	}
+	var named struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if json.Unmarshal(raw, &named) == nil && named.Function.Name != "" {
+		return &toolChoice{Type: "tool", Name: named.Function.Name}
+	}
+	return nil
+}
+
+// toChatCompletion translates a Messages API response into a chat completion.
+// It goes through JSON so the result carries the field metadata the SDK helpers rely on.
+func toChatCompletion(resp *messagesResponse) (*openai.ChatCompletion, error) {
+	var text strings.Builder
+	toolCalls := make([]map[string]any, 0)
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			toolCalls = append(toolCalls, map[string]any{
+				"id":       block.ID,
+				"type":     "function",
+				"function": map[string]any{"name": block.Name, "arguments": string(toolInput(string(block.Input)))},
+			})
+		}
+	}
+
+	msg := map[string]any{"role": "assistant", "content": text.String()}
+	if len(toolCalls) > 0 {
+		msg["tool_calls"] = toolCalls
+	}
+	raw := map[string]any{
+		"id":      resp.ID,
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   resp.Model,
+		"choices": []any{map[string]any{
+			"index":         0,
+			"message":       msg,
+			"finish_reason": finishReason(resp.StopReason),
+		}},
+		"usage": chatUsage(resp.Usage),
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var out openai.ChatCompletion
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("anthropic: failed to build completion: %w", err)
+	}
+	return &out, nil
+}
+
+// chatUsage reports cache reads and writes as part of the prompt, like OpenAI does.
+func chatUsage(u usage) map[string]any {
+	prompt := u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+	return map[string]any{
+		"prompt_tokens":         prompt,
+		"completion_tokens":     u.OutputTokens,
+		"total_tokens":          prompt + u.OutputTokens,
+		"prompt_tokens_details": map[string]any{"cached_tokens": u.CacheReadInputTokens},
+	}
+}
+
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "refusal":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
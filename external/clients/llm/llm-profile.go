@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported provider kinds for ProviderProfile.Type
+const (
+	TypeOpenAI    = "openai"    // OpenAI or any server exposing the OpenAI chat completions API (vLLM, llama.cpp, Ollama, ...)
+	TypeAnthropic = "anthropic" // Anthropic Messages API
+)
+
+// ProviderProfile describes how to reach one LLM backend.
+type ProviderProfile struct {
+	Name          string            `yaml:"name" json:"name"`
+	Type          string            `yaml:"type" json:"type"`                     // "openai" (default) or "anthropic"
+	BaseURL       string            `yaml:"base_url" json:"base_url"`             // empty = the provider's public API
+	APIKey        string            `yaml:"api_key" json:"api_key"`               // optional for servers on a custom base_url
+	Organization  string            `yaml:"organization" json:"organization"`     // OpenAI organization ID
+	Project       string            `yaml:"project" json:"project"`               // OpenAI project ID
+	Headers       map[string]string `yaml:"headers" json:"headers"`               // static headers sent with every request
+	Model         string            `yaml:"model" json:"model"`                   // chat model; required on a custom base_url
+	NativeTools   *bool             `yaml:"native_tools" json:"native_tools"`     // server supports tool calling (default true)
+	ToolEmulation bool              `yaml:"tool_emulation" json:"tool_emulation"` // tools via the system prompt, calls parsed from the reply text
+}
+
+// ProfilesFile is the on-disk layout of the LLM providers config file (YAML or JSON).
+type ProfilesFile struct {
+	Default   string            `yaml:"default" json:"default"` // profile used unless LLM_PROVIDER names another
+	Providers []ProviderProfile `yaml:"providers" json:"providers"`
+}
+
+// Capabilities are the features of a provider the chat loop adapts to.
+type Capabilities struct {
+	NativeTools bool // the backend accepts tools and returns tool calls
+}
+
+// SupportsNativeTools reports whether the backend has tool calling; profiles have it unless set to false.
+func (p *ProviderProfile) SupportsNativeTools() bool {
+	return p.NativeTools == nil || *p.NativeTools
+}
+
+// Validate checks the profile and fills in defaults (type and, on the public APIs, the model).
+func (p *ProviderProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("provider name required")
+	}
+	if p.Type == "" {
+		p.Type = TypeOpenAI
+	}
+	if p.Type != TypeOpenAI && p.Type != TypeAnthropic {
+		return fmt.Errorf("provider %s: unknown type %q (want openai or anthropic)", p.Name, p.Type)
+	}
+	if p.BaseURL == "" {
+		// the public APIs always need a key; local servers usually don't
+		if p.APIKey == "" {
+			return fmt.Errorf("provider %s: api_key required without base_url", p.Name)
+		}
+		if p.Model == "" {
+			p.Model = defaultModels[p.Type]
+		}
+	}
+	if p.Model == "" {
+		return fmt.Errorf("provider %s: model required with a custom base_url", p.Name)
+	}
+	return nil
+}
+
+// defaultModels are used for the public APIs when a profile sets no model
+var defaultModels = map[string]string{
+	TypeOpenAI:    "gpt-4.1",
+	TypeAnthropic: "claude-sonnet-4-5",
+}
+
+// LoadProfile reads the providers config file at path, interpolates ${ENV} references and
+// returns the profile called name (or the file's default, or its first profile, when name is empty).
+func LoadProfile(path, name string) (*ProviderProfile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LLM providers config %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM providers config %s: %w", path, err)
+	}
+	if missing := utils.ExpandEnvYAML(&root); len(missing) > 0 {
+		slog.Warn("LLM providers config references unset environment variables", "path", path, "vars", missing)
+	}
+
+	var file ProfilesFile
+	if err := root.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode LLM providers config %s: %w", path, err)
+	}
+	if len(file.Providers) == 0 {
+		return nil, fmt.Errorf("no providers in %s", path)
+	}
+
+	if name == "" {
+		name = file.Default
+	}
+	if name == "" {
+		name = file.Providers[0].Name
+	}
+	for i := range file.Providers {
+		p := file.Providers[i]
+		if p.Name != name {
+			continue
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid provider in %s: %w", path, err)
+		}
+		return &p, nil
+	}
+	return nil, fmt.Errorf("provider %q not found in %s", name, path)
+}
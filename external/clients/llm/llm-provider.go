@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Provider is a chat completion backend.
+// The OpenAI chat completion types are the common format of the app (history, tools, tool calls);
+// adapters for other APIs translate requests and responses to and from them.
+type Provider interface {
+	// Name identifies the provider in logs and commands ("openai", "anthropic").
+	Name() string
+	// Complete sends one request and returns the whole completion.
+	Complete(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+	// Stream sends one request and returns its chunks, which can be fed to an openai.ChatCompletionAccumulator.
+	Stream(ctx context.Context, params openai.ChatCompletionNewParams) ChunkStream
+	// ListModels returns the model IDs available to the configured account.
+	ListModels(ctx context.Context) ([]string, error)
+	// Capabilities reports the features the chat loop may use.
+	Capabilities() Capabilities
+}
+
+// ChunkStream iterates over the chunks of a streamed completion.
+// Errors are reported by Err once Next returns false.
+type ChunkStream interface {
+	Next() bool
+	Current() openai.ChatCompletionChunk
+	Err() error
+	Close() error
+}
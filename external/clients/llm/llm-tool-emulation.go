@@ -0,0 +1,263 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+)
+
+// toolPromptHeader introduces the tool list rendered into the system prompt of emulated requests.
+const toolPromptHeader = `# Tools
+
+You can call the tools listed below. To call a tool, reply with one block per call and nothing after the blocks:
+
+<tool_call>
+{"name": "<tool name>", "arguments": {<arguments as a JSON object matching the tool's parameters>}}
+</tool_call>
+
+You may write a short sentence before the blocks. Results come back in the next user message as
+<tool_result name="<tool name>" id="<call id>">...</tool_result> blocks; never write tool results yourself.
+When no tool is needed, or once you have the results, answer normally without any <tool_call> block.
+
+Available tools:`
+
+// emulatedProvider adds prompt-based tool calling to a provider whose models have none.
+type emulatedProvider struct {
+	inner Provider
+}
+
+// EmulateTools wraps inner so the chat loop can use tools with text-only models: tool schemas are
+// rendered into the system prompt, tool invocations written in the reply (<tool_call> blocks,
+// ReAct "Action:" lines or JSON) are parsed back into tool calls, and earlier tool calls and
+// results in the history are replayed as plain text.
+func EmulateTools(inner Provider) Provider {
+	return &emulatedProvider{inner: inner}
+}
+
+func (p *emulatedProvider) Name() string { return p.inner.Name() }
+
+func (p *emulatedProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.inner.ListModels(ctx)
+}
+
+func (p *emulatedProvider) Capabilities() Capabilities {
+	caps := p.inner.Capabilities()
+	caps.NativeTools = true
+	return caps
+}
+
+func (p *emulatedProvider) Complete(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	request, known := emulatedParams(params)
+	resp, err := p.inner.Complete(ctx, request)
+	if err != nil || len(known) == 0 || len(resp.Choices) == 0 {
+		return resp, err
+	}
+
+	content, calls := parseToolCalls(resp.Choices[0].Message.Content, known)
+	if len(calls) == 0 {
+		return resp, nil
+	}
+	slog.Info("emulated tool calls parsed", "provider", p.inner.Name(), "calls", len(calls))
+
+	raw := []byte(resp.RawJSON())
+	if len(raw) == 0 {
+		if raw, err = json.Marshal(resp); err != nil {
+			return nil, fmt.Errorf("tool emulation: failed to encode completion: %w", err)
+		}
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("tool emulation: failed to decode completion: %w", err)
+	}
+	choices, _ := out["choices"].([]any)
+	if len(choices) == 0 {
+		return resp, nil
+	}
+	choice, _ := choices[0].(map[string]any)
+	message, _ := choice["message"].(map[string]any)
+	if message == nil {
+		return resp, nil
+	}
+	message["content"] = content
+	message["tool_calls"] = toolCallsJSON(calls, false)
+	choice["finish_reason"] = "tool_calls"
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("tool emulation: failed to encode completion: %w", err)
+	}
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal(b, &completion); err != nil {
+		return nil, fmt.Errorf("tool emulation: failed to build completion: %w", err)
+	}
+	return &completion, nil
+}
+
+func (p *emulatedProvider) Stream(ctx context.Context, params openai.ChatCompletionNewParams) ChunkStream {
+	request, known := emulatedParams(params)
+	stream := p.inner.Stream(ctx, request)
+	if len(known) == 0 {
+		return stream
+	}
+	return newEmulatedStream(stream, known)
+}
+
+// emulatedParams rewrites params for a model without tool calling and returns the names of the
+// offered tools (none when tool_choice is "none", so replies are passed through untouched).
+func emulatedParams(params openai.ChatCompletionNewParams) (openai.ChatCompletionNewParams, map[string]bool) {
+	tools := params.Tools
+	choice := params.ToolChoice
+	params.Tools = nil
+	params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{}
+	params.ParallelToolCalls = param.Opt[bool]{}
+
+	messages := replayToolMessages(params.Messages)
+	known := make(map[string]bool)
+	if choice.OfAuto.Value != string(openai.ChatCompletionToolChoiceOptionAutoNone) {
+		var prompt strings.Builder
+		prompt.WriteString(toolPromptHeader)
+		for _, t := range tools {
+			if t.OfFunction == nil {
+				continue
+			}
+			fn := t.OfFunction.Function
+			known[fn.Name] = true
+			schema, _ := json.Marshal(fn.Parameters)
+			if fn.Parameters == nil {
+				schema = []byte(`{"type":"object","properties":{}}`)
+			}
+			fmt.Fprintf(&prompt, "\n\n## %s\n", fn.Name)
+			if fn.Description.Valid() && fn.Description.Value != "" {
+				prompt.WriteString(fn.Description.Value + "\n")
+			}
+			fmt.Fprintf(&prompt, "Parameters: %s", schema)
+		}
+		switch {
+		case choice.OfAuto.Value == string(openai.ChatCompletionToolChoiceOptionAutoRequired):
+			prompt.WriteString("\n\nYou must call at least one tool in your next reply.")
+		case choice.OfFunctionToolChoice != nil:
+			fmt.Fprintf(&prompt, "\n\nYou must call the %s tool in your next reply.", choice.OfFunctionToolChoice.Function.Name)
+		}
+		if len(known) > 0 {
+			messages = withSystemPrompt(messages, prompt.String())
+		}
+	}
+	params.Messages = messages
+	return params, known
+}
+
+// withSystemPrompt appends text to the leading system message, adding one when there is none.
+func withSystemPrompt(messages []openai.ChatCompletionMessageParamUnion, text string) []openai.ChatCompletionMessageParamUnion {
+	out := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages)+1)
+	if len(messages) > 0 && messages[0].OfSystem != nil {
+		if existing := messageText(messages[0].OfSystem.Content); existing != "" {
+			text = existing + "\n\n" + text
+		}
+		messages = messages[1:]
+	}
+	out = append(out, openai.SystemMessage(text))
+	return append(out, messages...)
+}
+
+// replayToolMessages turns assistant tool calls into <tool_call> text and runs of tool messages
+// into a single user message of <tool_result> blocks, which any chat model accepts.
+func replayToolMessages(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	out := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	names := make(map[string]string) // tool call ID -> tool name
+	var results []string
+	flush := func() {
+		if len(results) > 0 {
+			out = append(out, openai.UserMessage(strings.Join(results, "\n")))
+			results = nil
+		}
+	}
+
+	for _, m := range messages {
+		switch {
+		case m.OfTool != nil:
+			id := m.OfTool.ToolCallID
+			results = append(results, fmt.Sprintf("<tool_result name=%q id=%q>\n%s\n</tool_result>", names[id], id, messageText(m.OfTool.Content)))
+			continue
+		case m.OfAssistant != nil && len(m.OfAssistant.ToolCalls) > 0:
+			flush()
+			var text strings.Builder
+			text.WriteString(messageText(m.OfAssistant.Content))
+			for _, tc := range m.OfAssistant.ToolCalls {
+				if tc.OfFunction == nil {
+					continue
+				}
+				names[tc.OfFunction.ID] = tc.OfFunction.Function.Name
+				if text.Len() > 0 {
+					text.WriteString("\n")
+				}
+				call, _ := json.Marshal(map[string]any{"name": tc.OfFunction.Function.Name, "arguments": json.RawMessage(argumentsObject(tc.OfFunction.Function.Arguments))})
+				fmt.Fprintf(&text, "%s\n%s\n%s", toolCallOpen, call, toolCallClose)
+			}
+			out = append(out, openai.AssistantMessage(text.String()))
+			continue
+		}
+		flush()
+		out = append(out, m)
+	}
+	flush()
+	return out
+}
+
+// toolCallsJSON renders parsed calls as chat completion tool calls (stream deltas carry an index).
+func toolCallsJSON(calls []parsedToolCall, withIndex bool) []any {
+	prefix := fmt.Sprintf("call_emu_%d", time.Now().UnixNano())
+	out := make([]any, 0, len(calls))
+	for i, c := range calls {
+		call := map[string]any{
+			"id":       fmt.Sprintf("%s_%d", prefix, i),
+			"type":     "function",
+			"function": map[string]any{"name": c.Name, "arguments": c.Arguments},
+		}
+		if withIndex {
+			call["index"] = i
+		}
+		out = append(out, call)
+	}
+	return out
+}
+
+// messageText flattens string or text-part message content.
+func messageText(content any) string {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &parts) != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// argumentsObject returns arguments when they are a JSON object, else an empty object.
+func argumentsObject(arguments string) string {
+	var obj map[string]any
+	if json.Unmarshal([]byte(arguments), &obj) != nil {
+		return "{}"
+	}
+	return arguments
+}
@@ -0,0 +1,233 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// fakeProvider replies with fixed text, streamed in the given pieces, and records the last request.
+type fakeProvider struct {
+	pieces  []string
+	request openai.ChatCompletionNewParams
+}
+
+func (f *fakeProvider) Name() string                                 { return "fake" }
+func (f *fakeProvider) ListModels(context.Context) ([]string, error) { return nil, nil }
+func (f *fakeProvider) Capabilities() Capabilities                   { return Capabilities{} }
+
+func (f *fakeProvider) Complete(_ context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	f.request = params
+	var completion openai.ChatCompletion
+	err := json.Unmarshal(mustJSON(map[string]any{
+		"id": "c", "object": "chat.completion", "model": "m", "created": 1,
+		"choices": []any{map[string]any{"index": 0, "finish_reason": "stop",
+			"message": map[string]any{"role": "assistant", "content": strings.Join(f.pieces, "")}}},
+		"usage": map[string]any{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+	}), &completion)
+	return &completion, err
+}
+
+func (f *fakeProvider) Stream(_ context.Context, params openai.ChatCompletionNewParams) ChunkStream {
+	f.request = params
+	s := &sliceStream{}
+	add := func(choices []any, usage any) {
+		var chunk openai.ChatCompletionChunk
+		_ = json.Unmarshal(mustJSON(map[string]any{"id": "c", "object": "chat.completion.chunk", "model": "m", "created": 1,
+			"choices": choices, "usage": usage}), &chunk)
+		s.chunks = append(s.chunks, chunk)
+	}
+	add([]any{map[string]any{"index": 0, "delta": map[string]any{"role": "assistant", "content": ""}}}, nil)
+	for _, p := range f.pieces {
+		add([]any{map[string]any{"index": 0, "delta": map[string]any{"content": p}}}, nil)
+	}
+	add([]any{map[string]any{"index": 0, "delta": map[string]any{}, "finish_reason": "stop"}}, nil)
+	add([]any{}, map[string]any{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15})
+	return s
+}
+
+type sliceStream struct {
+	chunks  []openai.ChatCompletionChunk
+	current openai.ChatCompletionChunk
+}
+
+func (s *sliceStream) Next() bool {
+	if len(s.chunks) == 0 {
+		return false
+	}
+	s.current, s.chunks = s.chunks[0], s.chunks[1:]
+	return true
+}
+func (s *sliceStream) Current() openai.ChatCompletionChunk { return s.current }
+func (s *sliceStream) Err() error                          { return nil }
+func (s *sliceStream) Close() error                        { return nil }
+
+func mustJSON(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func emulationParams() openai.ChatCompletionNewParams {
+	return openai.ChatCompletionNewParams{
+		Model: "local",
+		Tools: []openai.ChatCompletionToolUnionParam{openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+			Name:        "weather__get",
+			Description: openai.String("Get the weather"),
+			Parameters:  openai.FunctionParameters{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+		})},
+		ParallelToolCalls: openai.Bool(true),
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage("You are helpful."),
+			openai.UserMessage("Weather in Paris?"),
+			{OfAssistant: &openai.ChatCompletionAssistantMessageParam{ToolCalls: []openai.ChatCompletionMessageToolCallUnionParam{{
+				OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+					ID:       "call_1",
+					Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{Name: "weather__get", Arguments: `{"city":"Paris"}`},
+				},
+			}}}},
+			openai.ToolMessage("sunny", "call_1"),
+			openai.UserMessage("And London?"),
+		},
+	}
+}
+
+func TestEmulatedParams(t *testing.T) {
+	request, known := emulatedParams(emulationParams())
+	if !known["weather__get"] {
+		t.Fatalf("known tools = %v", known)
+	}
+	if len(request.Tools) != 0 || request.ParallelToolCalls.Valid() {
+		t.Fatalf("native tool params still set")
+	}
+
+	var messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(mustJSON(request.Messages), &messages); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 5 {
+		t.Fatalf("got %d messages, want 5: %+v", len(messages), messages)
+	}
+	system := messages[0].Content
+	if messages[0].Role != "system" || !strings.HasPrefix(system, "You are helpful.\n\n# Tools") ||
+		!strings.Contains(system, "## weather__get\nGet the weather\nParameters: {") {
+		t.Errorf("system prompt = %q", system)
+	}
+	if messages[2].Role != "assistant" || messages[2].Content != "<tool_call>\n{\"arguments\":{\"city\":\"Paris\"},\"name\":\"weather__get\"}\n</tool_call>" {
+		t.Errorf("tool call replay = %+v", messages[2])
+	}
+	if messages[3].Role != "user" || messages[3].Content != "<tool_result name=\"weather__get\" id=\"call_1\">\nsunny\n</tool_result>" {
+		t.Errorf("tool result replay = %+v", messages[3])
+	}
+
+	none := emulationParams()
+	none.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String("none")}
+	request, known = emulatedParams(none)
+	if len(known) != 0 {
+		t.Errorf("tool_choice none still offers tools: %v", known)
+	}
+	if text := messageText(request.Messages[0].OfSystem.Content); text != "You are helpful." {
+		t.Errorf("tool_choice none system prompt = %q", text)
+	}
+}
+
+func TestEmulatedProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		pieces   []string
+		shown    string // text forwarded while streaming
+		content  string // final message content
+		toolCall string // "name args", "" for none
+	}{
+		{
+			name:     "tool_call split across chunks",
+			pieces:   []string{"Let me check.\n<tool", "_call>\n{\"name\":\"weather__get\",\"arguments\":{\"city\":\"London\"}}\n</tool_call>"},
+			shown:    "Let me check.\n",
+			content:  "Let me check.",
+			toolCall: `weather__get {"city":"London"}`,
+		},
+		{
+			name:     "JSON reply is held back",
+			pieces:   []string{"{\"name\":", "\"weather__get\",\"arguments\":{\"city\":\"Oslo\"}}"},
+			toolCall: `weather__get {"city":"Oslo"}`,
+		},
+		{
+			name:    "plain answer streams through",
+			pieces:  []string{"It is ", "sunny in London. Use `x", "` ok."},
+			shown:   "It is sunny in London. Use `x` ok.",
+			content: "It is sunny in London. Use `x` ok.",
+		},
+		{
+			name:    "code that is not a call",
+			pieces:  []string{"Example: ```json\n{\"a\":1}\n```"},
+			shown:   "Example: ```json\n{\"a\":1}\n```",
+			content: "Example: ```json\n{\"a\":1}\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := EmulateTools(&fakeProvider{pieces: tt.pieces})
+
+			resp, err := provider.Complete(context.Background(), emulationParams())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkEmulatedMessage(t, "complete", resp.Choices[0].Message, string(resp.Choices[0].FinishReason), tt.content, tt.toolCall)
+
+			acc := openai.ChatCompletionAccumulator{}
+			stream := provider.Stream(context.Background(), emulationParams())
+			var shown strings.Builder
+			for stream.Next() {
+				chunk := stream.Current()
+				acc.AddChunk(chunk)
+				if len(chunk.Choices) > 0 {
+					shown.WriteString(chunk.Choices[0].Delta.Content)
+				}
+			}
+			if err := stream.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if tt.toolCall == "" && shown.String() != tt.shown {
+				t.Errorf("stream shown %q, want %q", shown.String(), tt.shown)
+			}
+			if tt.toolCall != "" && !strings.HasPrefix(shown.String(), tt.shown) {
+				t.Errorf("stream shown %q, want prefix %q", shown.String(), tt.shown)
+			}
+			if acc.Usage.TotalTokens != 15 {
+				t.Errorf("stream usage = %d, want 15", acc.Usage.TotalTokens)
+			}
+			streamed := strings.TrimSpace(acc.Choices[0].Message.Content)
+			acc.Choices[0].Message.Content = streamed
+			checkEmulatedMessage(t, "stream", acc.Choices[0].Message, acc.Choices[0].FinishReason, tt.content, tt.toolCall)
+		})
+	}
+}
+
+func checkEmulatedMessage(t *testing.T, mode string, msg openai.ChatCompletionMessage, finish, content, toolCall string) {
+	t.Helper()
+	if msg.Content != content {
+		t.Errorf("%s: content = %q, want %q", mode, msg.Content, content)
+	}
+	if toolCall == "" {
+		if len(msg.ToolCalls) != 0 || finish != "stop" {
+			t.Errorf("%s: got %d tool calls, finish %q; want none, stop", mode, len(msg.ToolCalls), finish)
+		}
+		return
+	}
+	if len(msg.ToolCalls) != 1 || finish != "tool_calls" {
+		t.Fatalf("%s: got %d tool calls, finish %q; want 1, tool_calls", mode, len(msg.ToolCalls), finish)
+	}
+	call := msg.ToolCalls[0]
+	if got := call.Function.Name + " " + call.Function.Arguments; got != toolCall || call.ID == "" || call.Type != "function" {
+		t.Errorf("%s: tool call = %+v, want %s", mode, call, toolCall)
+	}
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+)
+
+// Markers of tool invocations in plain assistant text
+const (
+	toolCallOpen  = "<tool_call>"
+	toolCallClose = "</tool_call>"
+)
+
+var (
+	// reactAction matches a ReAct "Action: <tool>" line followed by "Action Input:"
+	reactAction = regexp.MustCompile(`(?m)^[ \t]*Action:[ \t]*(\S+)[ \t]*\r?\n[ \t]*Action Input:[ \t]*`)
+	// jsonFence matches a fenced code block that may hold a JSON invocation
+	jsonFence = regexp.MustCompile("(?s)```(?:json)?[ \t]*\r?\n(.*?)```")
+)
+
+// parsedToolCall is a tool invocation found in assistant text.
+type parsedToolCall struct {
+	Name      string
+	Arguments string // JSON object
+}
+
+// invocation is the JSON shape of one call; models use several spellings for the same fields.
+type invocation struct {
+	Name       string          `json:"name"`
+	Tool       string          `json:"tool"`
+	ToolName   string          `json:"tool_name"`
+	Function   string          `json:"function"`
+	Arguments  json.RawMessage `json:"arguments"`
+	Args       json.RawMessage `json:"args"`
+	Parameters json.RawMessage `json:"parameters"`
+	Input      json.RawMessage `json:"input"`
+	ToolInput  json.RawMessage `json:"tool_input"`
+}
+
+func (inv invocation) call() (parsedToolCall, bool) {
+	name := firstNonEmpty(inv.Name, inv.Tool, inv.ToolName, inv.Function)
+	if name == "" {
+		return parsedToolCall{}, false
+	}
+	for _, raw := range []json.RawMessage{inv.Arguments, inv.Args, inv.Parameters, inv.Input, inv.ToolInput} {
+		if len(raw) > 0 && string(raw) != "null" {
+			return parsedToolCall{Name: name, Arguments: argumentsJSON(raw)}, true
+		}
+	}
+	return parsedToolCall{Name: name, Arguments: "{}"}, true
+}
+
+// parseToolCalls extracts tool invocations from assistant text. It understands, in order:
+// <tool_call>{...}</tool_call> blocks, ReAct "Action:/Action Input:" lines, fenced JSON and a bare
+// JSON object. Except for the explicit <tool_call> blocks, only names in known count as calls, so
+// ordinary JSON in an answer is left alone. content is the text before the first invocation.
+func parseToolCalls(text string, known map[string]bool) (content string, calls []parsedToolCall) {
+	if start := strings.Index(text, toolCallOpen); start >= 0 {
+		rest := text[start:]
+		for {
+			open := strings.Index(rest, toolCallOpen)
+			if open < 0 {
+				break
+			}
+			body := rest[open+len(toolCallOpen):]
+			if end := strings.Index(body, toolCallClose); end >= 0 {
+				rest = body[end+len(toolCallClose):]
+				body = body[:end]
+			} else {
+				rest = "" // truncated at the end of the reply
+			}
+			calls = append(calls, decodeInvocations(body)...)
+		}
+		if len(calls) > 0 {
+			return strings.TrimSpace(text[:start]), calls
+		}
+	}
+
+	if loc := reactAction.FindStringSubmatchIndex(text); loc != nil {
+		name := text[loc[2]:loc[3]]
+		if known[name] {
+			input := text[loc[1]:]
+			if obs := strings.Index(input, "Observation:"); obs >= 0 {
+				input = input[:obs]
+			}
+			return strings.TrimSpace(text[:loc[0]]), []parsedToolCall{{Name: name, Arguments: argumentsJSON(json.RawMessage(strings.TrimSpace(input)))}}
+		}
+	}
+
+	for _, loc := range jsonFence.FindAllStringSubmatchIndex(text, -1) {
+		if found := knownCalls(decodeInvocations(text[loc[2]:loc[3]]), known); len(found) > 0 {
+			return strings.TrimSpace(text[:loc[0]]), found
+		}
+	}
+
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if found := knownCalls(decodeInvocations(trimmed), known); len(found) > 0 {
+			return "", found
+		}
+	}
+	return text, nil
+}
+
+// decodeInvocations reads one invocation or an array of them, repairing sloppy JSON.
+func decodeInvocations(body string) []parsedToolCall {
+	body = strings.TrimSpace(body)
+	if !json.Valid([]byte(body)) {
+		body = utils.RepairJSON(body)
+	}
+
+	var list []invocation
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		var single invocation
+		if err := json.Unmarshal([]byte(body), &single); err != nil {
+			return nil
+		}
+		list = []invocation{single}
+	}
+
+	calls := make([]parsedToolCall, 0, len(list))
+	for _, inv := range list {
+		if call, ok := inv.call(); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func knownCalls(calls []parsedToolCall, known map[string]bool) []parsedToolCall {
+	for _, c := range calls {
+		if !known[c.Name] {
+			return nil
+		}
+	}
+	return calls
+}
+
+// argumentsJSON returns arguments as a JSON object string: objects as-is, strings holding JSON
+// unwrapped, and anything else for the tool executor to repair or reject.
+func argumentsJSON(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	// a ReAct input may be followed by more text; keep the first JSON value
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	var v json.RawMessage
+	if err := dec.Decode(&v); err == nil {
+		return string(v)
+	}
+	return string(raw)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseToolCalls(t *testing.T) {
+	known := map[string]bool{"weather__get": true, "notes__add": true}
+	tests := []struct {
+		name    string
+		text    string
+		content string
+		calls   []parsedToolCall
+	}{
+		{
+			name:    "plain answer",
+			text:    "It is sunny in Paris.",
+			content: "It is sunny in Paris.",
+		},
+		{
+			name:    "tool_call block with preamble",
+			text:    "Let me check.\n<tool_call>\n{\"name\": \"weather__get\", \"arguments\": {\"city\": \"Paris\"}}\n</tool_call>",
+			content: "Let me check.",
+			calls:   []parsedToolCall{{"weather__get", `{"city": "Paris"}`}},
+		},
+		{
+			name: "several blocks",
+			text: "<tool_call>{\"name\":\"weather__get\",\"arguments\":{\"city\":\"A\"}}</tool_call>\n<tool_call>{\"name\":\"notes__add\",\"arguments\":{\"text\":\"B\"}}</tool_call>",
+			calls: []parsedToolCall{
+				{"weather__get", `{"city":"A"}`},
+				{"notes__add", `{"text":"B"}`},
+			},
+		},
+		{
+			name:  "unclosed block with truncated JSON",
+			text:  "<tool_call>\n{\"name\":\"weather__get\",\"arguments\":{\"city\":\"Par",
+			calls: []parsedToolCall{{"weather__get", `{"city":"Par"}`}},
+		},
+		{
+			name:  "block names an unknown tool",
+			text:  "<tool_call>{\"name\":\"other\",\"arguments\":{}}</tool_call>",
+			calls: []parsedToolCall{{"other", `{}`}},
+		},
+		{
+			name:  "alternative keys and string arguments",
+			text:  "<tool_call>{\"tool\":\"weather__get\",\"args\":\"{\\\"city\\\":\\\"Oslo\\\"}\"}</tool_call>",
+			calls: []parsedToolCall{{"weather__get", `{"city":"Oslo"}`}},
+		},
+		{
+			name:  "missing arguments",
+			text:  "<tool_call>{\"name\":\"weather__get\"}</tool_call>",
+			calls: []parsedToolCall{{"weather__get", `{}`}},
+		},
+		{
+			name:    "ReAct",
+			text:    "Thought: I need the weather.\nAction: weather__get\nAction Input: {\"city\": \"Rome\"}\nObservation:",
+			content: "Thought: I need the weather.",
+			calls:   []parsedToolCall{{"weather__get", `{"city": "Rome"}`}},
+		},
+		{
+			name:    "ReAct with an unknown tool",
+			text:    "Action: search\nAction Input: {\"q\": \"x\"}",
+			content: "Action: search\nAction Input: {\"q\": \"x\"}",
+		},
+		{
+			name:  "fenced JSON",
+			text:  "```json\n{\"name\": \"notes__add\", \"parameters\": {\"text\": \"hi\"}}\n```",
+			calls: []parsedToolCall{{"notes__add", `{"text": "hi"}`}},
+		},
+		{
+			name:    "fenced JSON that is not a call",
+			text:    "Here is the data:\n```json\n{\"city\": \"Paris\"}\n```",
+			content: "Here is the data:\n```json\n{\"city\": \"Paris\"}\n```",
+		},
+		{
+			name:  "bare JSON array",
+			text:  "[{\"name\":\"weather__get\",\"arguments\":{\"city\":\"A\"}},{\"name\":\"weather__get\",\"arguments\":{\"city\":\"B\"}}]",
+			calls: []parsedToolCall{{"weather__get", `{"city":"A"}`}, {"weather__get", `{"city":"B"}`}},
+		},
+		{
+			name:    "bare JSON with an unknown tool",
+			text:    "{\"name\":\"Paris\",\"arguments\":{}}",
+			content: "{\"name\":\"Paris\",\"arguments\":{}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, calls := parseToolCalls(tt.text, known)
+			if content != tt.content {
+				t.Errorf("content = %q, want %q", content, tt.content)
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("calls = %+v, want %+v", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// holdMarkers start text that may turn out to be a tool invocation; streaming pauses there.
+var holdMarkers = []string{toolCallOpen, "Action:", "```"}
+
+// emulatedStream forwards the text of a streamed reply as it arrives, holding it back from the
+// first possible tool invocation on. At the end of the stream the held text is parsed and sent
+// either as tool call deltas or, when it was no invocation after all, as the remaining text.
+type emulatedStream struct {
+	inner   ChunkStream
+	known   map[string]bool
+	pending []openai.ChatCompletionChunk
+	current openai.ChatCompletionChunk
+	err     error
+	done    bool
+
+	text   strings.Builder
+	sent   int  // bytes of text already forwarded
+	held   bool // a marker was seen: forward nothing more until the end
+	finish string
+	usage  *openai.ChatCompletionChunk // usage-only chunk, sent last
+	meta   map[string]any              // id, model and created of the stream
+}
+
+func newEmulatedStream(inner ChunkStream, known map[string]bool) *emulatedStream {
+	return &emulatedStream{inner: inner, known: known}
+}
+
+func (s *emulatedStream) Next() bool {
+	for len(s.pending) == 0 {
+		if s.err != nil || s.done {
+			return false
+		}
+		if !s.inner.Next() {
+			s.done = true
+			if s.err = s.inner.Err(); s.err == nil {
+				s.finishStream()
+			}
+			continue
+		}
+		s.handle(s.inner.Current())
+	}
+	s.current, s.pending = s.pending[0], s.pending[1:]
+	return true
+}
+
+func (s *emulatedStream) Current() openai.ChatCompletionChunk { return s.current }
+
+func (s *emulatedStream) Err() error { return s.err }
+
+func (s *emulatedStream) Close() error { return s.inner.Close() }
+
+// handle forwards chunk with its content cut to the text that is safe to show and its finish
+// reason removed; the finish reason and a usage-only chunk are sent once the reply is parsed.
+func (s *emulatedStream) handle(chunk openai.ChatCompletionChunk) {
+	raw := s.decode(chunk)
+	if raw == nil {
+		return
+	}
+	choices, _ := raw["choices"].([]any)
+	if len(choices) == 0 {
+		if chunk.JSON.Usage.Valid() {
+			s.usage = &chunk
+			return
+		}
+		s.pending = append(s.pending, chunk)
+		return
+	}
+
+	choice, _ := choices[0].(map[string]any)
+	if reason, ok := choice["finish_reason"].(string); ok && reason != "" {
+		s.finish = reason
+		delete(choice, "finish_reason")
+	}
+	if delta, ok := choice["delta"].(map[string]any); ok {
+		if content, ok := delta["content"].(string); ok {
+			s.text.WriteString(content)
+			if next := s.forwardable(); next != "" {
+				delta["content"] = next
+			} else {
+				delete(delta, "content")
+			}
+		}
+	}
+	s.queue(raw)
+}
+
+// forwardable returns the new text that cannot be part of a tool invocation.
+func (s *emulatedStream) forwardable() string {
+	if s.held {
+		return ""
+	}
+	text := s.text.String()
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		s.held = true // the whole reply may be a JSON invocation
+		return ""
+	}
+
+	end := len(text)
+	for _, marker := range holdMarkers {
+		if i := strings.Index(text[s.sent:], marker); i >= 0 && s.sent+i < end {
+			end = s.sent + i
+			s.held = true
+		}
+	}
+	if !s.held {
+		// keep back a suffix that may grow into a marker
+		for _, marker := range holdMarkers {
+			for n := min(len(marker)-1, len(text)-s.sent); n > 0; n-- {
+				if strings.HasSuffix(text, marker[:n]) {
+					end = min(end, len(text)-n)
+					break
+				}
+			}
+		}
+	}
+	if end <= s.sent {
+		return ""
+	}
+	next := text[s.sent:end]
+	s.sent = end
+	return next
+}
+
+// finishStream sends what was held back: tool call deltas or the remaining text, then the
+// finish reason and usage.
+func (s *emulatedStream) finishStream() {
+	text := s.text.String()
+	content, calls := parseToolCalls(text, s.known)
+
+	if len(calls) > 0 {
+		slog.Info("emulated tool calls parsed", "calls", len(calls))
+		if len(content) > s.sent && strings.HasPrefix(content, text[:s.sent]) {
+			s.queueDelta(map[string]any{"content": content[s.sent:]}, "")
+		}
+		s.queueDelta(map[string]any{"tool_calls": toolCallsJSON(calls, true)}, "")
+		s.finish = "tool_calls"
+	} else if s.sent < len(text) {
+		s.queueDelta(map[string]any{"content": text[s.sent:]}, "")
+	}
+
+	if s.finish == "" {
+		s.finish = "stop"
+	}
+	s.queueDelta(map[string]any{}, s.finish)
+	if s.usage != nil {
+		s.pending = append(s.pending, *s.usage)
+	}
+}
+
+func (s *emulatedStream) decode(chunk openai.ChatCompletionChunk) map[string]any {
+	raw := []byte(chunk.RawJSON())
+	if len(raw) == 0 {
+		var err error
+		if raw, err = json.Marshal(chunk); err != nil {
+			s.err = fmt.Errorf("tool emulation: failed to encode chunk: %w", err)
+			return nil
+		}
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		s.err = fmt.Errorf("tool emulation: failed to decode chunk: %w", err)
+		return nil
+	}
+	if s.meta == nil {
+		s.meta = map[string]any{"id": out["id"], "model": out["model"], "created": out["created"]}
+	}
+	return out
+}
+
+func (s *emulatedStream) queueDelta(delta map[string]any, finish string) {
+	choice := map[string]any{"index": 0, "delta": delta}
+	if finish != "" {
+		choice["finish_reason"] = finish
+	}
+	raw := map[string]any{"object": "chat.completion.chunk", "choices": []any{choice}}
+	for k, v := range s.meta {
+		raw[k] = v
+	}
+	s.queue(raw)
+}
+
+// queue adds a chunk built through JSON so the accumulator sees which delta fields are set.
+func (s *emulatedStream) queue(raw map[string]any) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		s.err = err
+		return
+	}
+	var chunk openai.ChatCompletionChunk
+	if err := json.Unmarshal(b, &chunk); err != nil {
+		s.err = fmt.Errorf("tool emulation: failed to build chunk: %w", err)
+		return
+	}
+	s.pending = append(s.pending, chunk)
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ApprovalMode decides whether a tool call runs without asking the user.
+type ApprovalMode string
+
+const (
+	ApprovalAllow ApprovalMode = "allow" // run without asking
+	ApprovalAsk   ApprovalMode = "ask"   // ask the user before every call
+	ApprovalDeny  ApprovalMode = "deny"  // never run
+)
+
+// ApprovalConfig holds the per-server approval rules.
+// Tool keys are MCP tool names and may use glob patterns ("API-get-*").
+type ApprovalConfig struct {
+	Default ApprovalMode            `yaml:"default" json:"default"` // empty = derive from the tool's annotations
+	Tools   map[string]ApprovalMode `yaml:"tools" json:"tools"`
+}
+
+// validate checks the modes and patterns of the approval rules.
+func (c *ApprovalConfig) validate() error {
+	if c.Default != "" && !c.Default.valid() {
+		return fmt.Errorf("invalid approval default %q (want allow, ask or deny)", c.Default)
+	}
+	for pattern, mode := range c.Tools {
+		if !mode.valid() {
+			return fmt.Errorf("invalid approval mode %q for tool %q (want allow, ask or deny)", mode, pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid approval tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (a ApprovalMode) valid() bool {
+	return a == ApprovalAllow || a == ApprovalAsk || a == ApprovalDeny
+}
+
+// ToolApprovalRequest describes a tool call waiting for the user's decision.
+type ToolApprovalRequest struct {
+	Server      string
+	Tool        string
+	Description string
+	Annotations *mcp.ToolAnnotations
+	Args        map[string]any
+}
+
+// ToolApprovalDecision is the user's answer to a ToolApprovalRequest.
+type ToolApprovalDecision struct {
+	Approved bool
+	Args     map[string]any // arguments to use; nil keeps the requested ones
+	Remember bool           // persist the decision for this server/tool
+}
+
+// ToolApprover asks the user whether a tool call may run. It returns ctx's error when the turn is
+// cancelled before the user answers.
+type ToolApprover func(ctx context.Context, req ToolApprovalRequest) (ToolApprovalDecision, error)
+
+// ApprovalStore persists remembered decisions ("always allow" / "never") across sessions.
+type ApprovalStore struct {
+	path string
+
+	mu        sync.Mutex
+	decisions map[string]ApprovalMode // "server/tool" -> mode
+}
+
+// LoadApprovalStore reads remembered decisions from path; a missing file starts an empty store.
+func LoadApprovalStore(path string) (*ApprovalStore, error) {
+	s := &ApprovalStore{path: path, decisions: make(map[string]ApprovalMode)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approvals file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(b, &s.decisions); err != nil {
+		return nil, fmt.Errorf("failed to parse approvals file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func approvalKey(server, tool string) string {
+	return server + "/" + tool
+}
+
+// get returns the remembered decision for server/tool.
+func (s *ApprovalStore) get(server, tool string) (ApprovalMode, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mode, ok := s.decisions[approvalKey(server, tool)]
+	return mode, ok
+}
+
+// remember records a decision and writes the store back to disk.
+func (s *ApprovalStore) remember(server, tool string, mode ApprovalMode) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decisions[approvalKey(server, tool)] = mode
+
+	b, err := json.MarshalIndent(s.decisions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(s.path, b, 0o600)
+}
+
+// SetToolApprover installs the interactive approver and the store of remembered decisions.
+func (m *Manager) SetToolApprover(approver ToolApprover, store *ApprovalStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approver = approver
+	m.approvals = store
+}
+
+// findTool returns the raw descriptor of a server's tool, or nil.
+func (m *Manager) findTool(server, tool string) *mcp.Tool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.tools[server] {
+		if t.Name == tool {
+			return t
+		}
+	}
+	return nil
+}
+
+// approvalMode resolves the mode of a tool: remembered decision, then the server's tool rules
+// (exact name before patterns), then the server default, then the tool's annotations.
+func (m *Manager) approvalMode(server, tool string) ApprovalMode {
+	m.mu.RLock()
+	store := m.approvals
+	cfg := m.configs[server]
+	m.mu.RUnlock()
+
+	if mode, ok := store.get(server, tool); ok {
+		return mode
+	}
+
+	if cfg != nil && cfg.Approval != nil {
+		if mode, ok := lookupToolRule(cfg.Approval.Tools, tool); ok {
+			return mode
+		}
+		if cfg.Approval.Default != "" {
+			return cfg.Approval.Default
+		}
+	}
+
+	// synthetic resource tools only read
+	if m.isResourceTool(server, tool) {
+		return ApprovalAllow
+	}
+
+	// MCP defaults: a tool that is not read-only is assumed destructive unless it says otherwise
+	t := m.findTool(server, tool)
+	if t == nil || t.Annotations == nil {
+		return ApprovalAsk
+	}
+	if t.Annotations.ReadOnlyHint {
+		return ApprovalAllow
+	}
+	if t.Annotations.DestructiveHint != nil && !*t.Annotations.DestructiveHint {
+		return ApprovalAllow
+	}
+	return ApprovalAsk
+}
+
+// approveToolCall applies the approval policy to a call. It returns the arguments to run with,
+// or a reason the call must not run; the error is set when ctx ends while the user is asked.
+func (m *Manager) approveToolCall(ctx context.Context, server, tool string, args map[string]any) (map[string]any, string, error) {
+	mode := m.approvalMode(server, tool)
+	switch mode {
+	case ApprovalAllow:
+		return args, "", nil
+	case ApprovalDeny:
+		slog.Info("tool call denied by policy", "server", server, "tool", tool)
+		return nil, "tool call denied by the user's approval policy", nil
+	}
+
+	m.mu.RLock()
+	approver := m.approver
+	store := m.approvals
+	m.mu.RUnlock()
+	if approver == nil {
+		slog.Warn("tool call needs approval but no approver is set", "server", server, "tool", tool)
+		return nil, "tool call requires user approval, which is unavailable", nil
+	}
+
+	req := ToolApprovalRequest{Server: server, Tool: tool, Args: args}
+	if t := m.findTool(server, tool); t != nil {
+		req.Description = t.Description
+		req.Annotations = t.Annotations
+	}
+	decision, err := approver(ctx, req)
+	if err != nil {
+		slog.Info("tool call approval cancelled", "server", server, "tool", tool, "error", err)
+		return nil, "", fmt.Errorf("approval of %s cancelled: %w", tool, err)
+	}
+
+	if decision.Remember {
+		remembered := ApprovalDeny
+		if decision.Approved {
+			remembered = ApprovalAllow
+		}
+		if err := store.remember(server, tool, remembered); err != nil {
+			slog.Error("failed to persist approval decision", "server", server, "tool", tool, "error", err)
+		}
+	}
+
+	if !decision.Approved {
+		slog.Info("tool call denied by user", "server", server, "tool", tool)
+		return nil, "tool call denied by the user", nil
+	}
+	if decision.Args != nil {
+		slog.Info("tool call arguments edited by user", "server", server, "tool", tool)
+		return decision.Args, "", nil
+	}
+	return args, "", nil
+}
@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+)
+
+// acquireCallSlot blocks until the server has a free tool-call slot (max_concurrency) or ctx is done.
+// The returned func releases the slot; servers without a limit never block.
+func (m *Manager) acquireCallSlot(ctx context.Context, server string) (func(), error) {
+	m.mu.Lock()
+	cfg, ok := m.configs[server]
+	if !ok || cfg.MaxConcurrency <= 0 {
+		m.mu.Unlock()
+		return func() {}, nil
+	}
+	slots, ok := m.limits[server]
+	if !ok || cap(slots) != cfg.MaxConcurrency {
+		slots = make(chan struct{}, cfg.MaxConcurrency)
+		m.limits[server] = slots
+	}
+	m.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+	}
+
+	slog.Info("tool call waiting for a free slot", "server", server, "max_concurrency", cfg.MaxConcurrency)
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
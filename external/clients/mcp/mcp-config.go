@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported transport kinds for MCPServerConfig.Transport
+const (
+	TransportStreamable = "streamable"
+	TransportSSE        = "sse"
+	TransportStdio      = "stdio"
+)
+
+// MCPConfigFile is the on-disk layout of the MCP servers config file (YAML or JSON).
+type MCPConfigFile struct {
+	Servers []MCPServerConfig `yaml:"servers" json:"servers"`
+}
+
+// LoadServerConfigs reads the MCP servers config file at path, interpolates ${ENV}
+// references, validates every entry and returns the enabled servers in file order.
+// YAML is used for parsing, so plain JSON files are accepted as well.
+func LoadServerConfigs(path string) ([]MCPServerConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP config %s: %w", path, err)
+	}
+
+	// Interpolate scalar values only, so comments and keys are left untouched
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse MCP config %s: %w", path, err)
+	}
+	missing := utils.ExpandEnvYAML(&root)
+	if len(missing) > 0 {
+		slog.Warn("MCP config references unset environment variables", "path", path, "vars", missing)
+	}
+
+	var file MCPConfigFile
+	if err := root.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode MCP config %s: %w", path, err)
+	}
+
+	// check the whole file before logging anything, so a bad entry doesn't follow "loaded" lines
+	seen := make(map[string]bool, len(file.Servers))
+	enabled := make([]MCPServerConfig, 0, len(file.Servers))
+	for i := range file.Servers {
+		cfg := file.Servers[i]
+		if !cfg.IsEnabled() {
+			continue
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid MCP server #%d in %s: %w", i+1, path, err)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate MCP server name %q in %s", cfg.Name, path)
+		}
+		seen[cfg.Name] = true
+		enabled = append(enabled, cfg)
+	}
+
+	for _, cfg := range file.Servers {
+		if !cfg.IsEnabled() {
+			slog.Info("MCP server disabled in config; skipping", "server", cfg.Name)
+		}
+	}
+	for _, cfg := range enabled {
+		slog.Info("MCP server loaded from config", "config", cfg)
+	}
+	return enabled, nil
+}
+
+// IsEnabled reports whether the server should be registered; servers are enabled unless set to false.
+func (c *MCPServerConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// Validate checks the config and fills in defaults (e.g. the transport kind).
+func (c *MCPServerConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("server name required")
+	}
+	if strings.ContainsAny(c.Name, ": \t") {
+		// used as /<server>:<prompt> in the REPL
+		return fmt.Errorf("server name %q must not contain ':' or whitespace", c.Name)
+	}
+	if c.Transport == "" {
+		c.Transport = TransportStreamable
+	}
+	if c.ConnectTimeout < 0 {
+		return fmt.Errorf("server %s: connect_timeout must not be negative", c.Name)
+	}
+	if c.MaxConcurrency < 0 {
+		return fmt.Errorf("server %s: max_concurrency must not be negative", c.Name)
+	}
+	if c.Tools != nil {
+		if err := c.Tools.validate(); err != nil {
+			return fmt.Errorf("server %s: %w", c.Name, err)
+		}
+	}
+	if c.Approval != nil {
+		if err := c.Approval.validate(); err != nil {
+			return fmt.Errorf("server %s: %w", c.Name, err)
+		}
+	}
+
+	if c.APIKey != "" && c.BasicAuth != nil {
+		return fmt.Errorf("server %s: api_key and basic_auth are mutually exclusive", c.Name)
+	}
+	if c.BasicAuth != nil && c.BasicAuth.Username == "" {
+		return fmt.Errorf("server %s: basic_auth.username required", c.Name)
+	}
+
+	switch c.Transport {
+	case TransportStreamable, TransportSSE:
+		if c.Endpoint == "" {
+			return fmt.Errorf("server %s: endpoint required", c.Name)
+		}
+		u, err := url.Parse(c.Endpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("server %s: invalid endpoint %q", c.Name, c.Endpoint)
+		}
+	case TransportStdio:
+		if c.Command == "" {
+			return fmt.Errorf("server %s: command required for stdio transport", c.Name)
+		}
+	default:
+		return fmt.Errorf("server %s: unsupported transport %q", c.Name, c.Transport)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadServerConfigsChecksBeforeLogging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp-servers.yaml")
+	config := `servers:
+  - name: a
+    endpoint: http://127.0.0.1:1/mcp
+  - name: b
+    endpoint: http://127.0.0.1:2/mcp
+  - name: a
+    endpoint: http://127.0.0.1:3/mcp
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	_, err := LoadServerConfigs(path)
+	if err == nil || !strings.Contains(err.Error(), `duplicate MCP server name "a"`) {
+		t.Fatalf("LoadServerConfigs error = %v, want a duplicate name error", err)
+	}
+	if strings.Contains(logs.String(), "MCP server loaded from config") {
+		t.Fatalf("servers logged as loaded from a rejected file:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ContentToText renders a single MCP content block as plain text for the model.
+// Binary payloads (images, audio, blobs) are summarized instead of inlined as base64.
+func ContentToText(c mcp.Content) string {
+	switch v := c.(type) {
+	case *mcp.TextContent:
+		return v.Text
+	case *mcp.ImageContent:
+		return fmt.Sprintf("[image %s, %d bytes]", v.MIMEType, len(v.Data))
+	case *mcp.AudioContent:
+		return fmt.Sprintf("[audio %s, %d bytes]", v.MIMEType, len(v.Data))
+	case *mcp.ResourceLink:
+		if v.Description != "" {
+			return fmt.Sprintf("[resource link %s (%s): %s]", v.URI, v.Name, v.Description)
+		}
+		return fmt.Sprintf("[resource link %s (%s)]", v.URI, v.Name)
+	case *mcp.EmbeddedResource:
+		if v.Resource == nil {
+			return ""
+		}
+		if v.Resource.Text != "" || len(v.Resource.Blob) == 0 {
+			return v.Resource.Text
+		}
+		return fmt.Sprintf("[binary resource %s (%s), %d bytes]", v.Resource.URI, v.Resource.MIMEType, len(v.Resource.Blob))
+	default:
+		return fmt.Sprintf("[unsupported content %T]", c)
+	}
+}
+
+// defaultMaxResultChars caps tool results so a single call cannot flood the context window
+const defaultMaxResultChars = 20000
+
+// ToolImage is an image returned by a tool, ready to be sent as an image_url part.
+type ToolImage struct {
+	MIMEType string
+	DataURL  string
+}
+
+// ToolResult is a tool call result converted for the model.
+type ToolResult struct {
+	Text    string      // content for the tool message
+	IsError bool        // the tool reported a failure (CallToolResult.IsError)
+	Images  []ToolImage // images to attach on a follow-up user message for vision models
+}
+
+// ConvertToolResult flattens a CallToolResult into text. Structured content is preferred when present,
+// images are collected for vision models, and the text is capped at maxChars.
+func ConvertToolResult(res *mcp.CallToolResult, maxChars int) *ToolResult {
+	out := &ToolResult{IsError: res.IsError}
+
+	texts := make([]string, 0, len(res.Content))
+	for _, c := range res.Content {
+		if img, ok := c.(*mcp.ImageContent); ok {
+			out.Images = append(out.Images, ToolImage{
+				MIMEType: img.MIMEType,
+				DataURL:  "data:" + img.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(img.Data),
+			})
+		}
+		if t := ContentToText(c); t != "" {
+			texts = append(texts, t)
+		}
+	}
+
+	if res.StructuredContent != nil {
+		if b, err := json.Marshal(res.StructuredContent); err == nil {
+			out.Text = string(b)
+		}
+	}
+	if out.Text == "" {
+		out.Text = strings.Join(texts, "\n")
+	}
+	if out.IsError && out.Text == "" {
+		out.Text = "tool reported an error without details"
+	}
+
+	out.Text = truncateResult(out.Text, maxChars)
+	return out
+}
+
+// truncateResult cuts s to maxChars runes and notes how much was dropped.
+func truncateResult(s string, maxChars int) string {
+	if maxChars <= 0 {
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= maxChars {
+		return s
+	}
+	return string(r[:maxChars]) + fmt.Sprintf("\n…[truncated %d characters]", len(r)-maxChars)
+}
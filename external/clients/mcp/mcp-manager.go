@@ -3,9 +3,11 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,10 +18,29 @@ import (
 
 // ServerConfig describes an MCP server to register
 type MCPServerConfig struct {
-	Name     string // unique name used as map key
-	Endpoint string // SSE / HTTP endpoint or base url
-	APIKey   string // optional auth token (if required)
-	// add other options as needed (timeout, transport type, etc.)
+	Name           string                   `yaml:"name" json:"name"`                         // unique name used as map key
+	Endpoint       string                   `yaml:"endpoint" json:"endpoint"`                 // SSE / HTTP endpoint or base url
+	Transport      string                   `yaml:"transport" json:"transport"`               // "streamable" (default), "sse" or "stdio"
+	APIKey         string                   `yaml:"api_key" json:"api_key"`                   // optional bearer token sent as "Authorization: Bearer <key>"
+	BasicAuth      *BasicAuthConfig         `yaml:"basic_auth" json:"basic_auth"`             // optional HTTP basic auth
+	Headers        map[string]string        `yaml:"headers" json:"headers"`                   // static headers sent with every request
+	ConnectTimeout time.Duration            `yaml:"connect_timeout" json:"connect_timeout"`   // per-server connect + list tools timeout
+	HealthInterval time.Duration            `yaml:"health_interval" json:"health_interval"`   // ping interval of the health supervisor (default 30s)
+	Sampling       *SamplingConfig          `yaml:"sampling" json:"sampling"`                 // allow the server to request LLM completions
+	MaxResultChars int                      `yaml:"max_result_chars" json:"max_result_chars"` // cap for tool results sent to the model (default 20000)
+	ToolTimeout    time.Duration            `yaml:"tool_timeout" json:"tool_timeout"`         // per-call timeout for this server's tools (default 2m)
+	ToolTimeouts   map[string]time.Duration `yaml:"tool_timeouts" json:"tool_timeouts"`       // per-tool overrides, keyed by MCP tool name
+	MaxConcurrency int                      `yaml:"max_concurrency" json:"max_concurrency"`   // concurrent tool calls allowed on this server (0 = unlimited)
+	Approval       *ApprovalConfig          `yaml:"approval" json:"approval"`                 // allow / ask / deny rules for this server's tools
+	Tools          *ToolsConfig             `yaml:"tools" json:"tools"`                       // include/exclude filters and per-tool overrides
+	StrictSchemas  bool                     `yaml:"strict_schemas" json:"strict_schemas"`     // send tool schemas in OpenAI strict mode when compatible
+	Enabled        *bool                    `yaml:"enabled" json:"enabled"`                   // nil means enabled
+
+	// stdio transport: the server is spawned as a child process
+	Command string            `yaml:"command" json:"command"`   // executable to run
+	Args    []string          `yaml:"args" json:"args"`         // command arguments
+	Env     map[string]string `yaml:"env" json:"env"`           // extra environment on top of os.Environ()
+	WorkDir string            `yaml:"work_dir" json:"work_dir"` // working directory of the child process
 }
 
 // Manager is the central singleton that holds multiple MCP sessions and tool schemas.
@@ -35,6 +56,36 @@ type Manager struct {
 	// schemas map: serverName -> []openai.ChatCompletionToolUnionParam (OpenAI tool schemas)
 	schemas map[string][]openai.ChatCompletionToolUnionParam
 
+	// prompts map: serverName -> []*mcp.Prompt (prompt templates published by the server)
+	prompts map[string][]*mcp.Prompt
+
+	// procs map: serverName -> child process for stdio servers
+	procs map[string]*os.Process
+
+	// configs map: serverName -> config used to (re)connect
+	configs map[string]*MCPServerConfig
+
+	// healthy map: serverName -> last known health; unhealthy servers are hidden from the model
+	healthy map[string]bool
+
+	// supervisors map: serverName -> health supervisor
+	supervisors map[string]*supervisor
+
+	// limits map: serverName -> semaphore enforcing max_concurrency for tool calls
+	limits map[string]chan struct{}
+
+	// sampler serves sampling/createMessage requests for servers that enable it (nil = sampling off)
+	sampler *Sampler
+
+	// approver asks the user about tool calls whose approval mode is "ask" (nil = such calls are denied)
+	approver ToolApprover
+
+	// approvals holds decisions the user asked to remember
+	approvals *ApprovalStore
+
+	// names maps OpenAI function names to (server, tool) and back
+	names *toolNames
+
 	// order keeps server names in registration order
 	order []string
 }
@@ -48,202 +99,216 @@ var (
 func GetManager() *Manager {
 	managerOnce.Do(func() {
 		managerInstance = &Manager{
-			sessions: make(map[string]*mcp.ClientSession),
-			tools:    make(map[string][]*mcp.Tool),
-			schemas:  make(map[string][]openai.ChatCompletionToolUnionParam),
-			order:    make([]string, 0),
+			sessions:    make(map[string]*mcp.ClientSession),
+			tools:       make(map[string][]*mcp.Tool),
+			schemas:     make(map[string][]openai.ChatCompletionToolUnionParam),
+			prompts:     make(map[string][]*mcp.Prompt),
+			procs:       make(map[string]*os.Process),
+			configs:     make(map[string]*MCPServerConfig),
+			healthy:     make(map[string]bool),
+			supervisors: make(map[string]*supervisor),
+			limits:      make(map[string]chan struct{}),
+			names:       newToolNames(),
+			order:       make([]string, 0),
 		}
 	})
 	return managerInstance
 }
 
-// normalizeJSONSchema traverses and fixes common incompatibilities for OpenAI tool parameter schemas.
-// - Ensures array "items" is an object schema; if provided as string or empty, default to {"type":"string"}
-// - Recursively normalizes nested properties and items
-func normalizeJSONSchema(node any) any {
-	switch v := node.(type) {
-	case map[string]any:
-		// Recurse properties
-		if propsRaw, ok := v["properties"].(map[string]any); ok {
-			for k, pv := range propsRaw {
-				propsRaw[k] = normalizeJSONSchema(pv)
-			}
-		}
-		// Handle array items shape
-		if t, ok := v["type"].(string); ok && t == "array" {
-			items, exists := v["items"]
-			if !exists || items == nil {
-				v["items"] = map[string]any{"type": "string"}
-			} else {
-				switch it := items.(type) {
-				case map[string]any:
-					v["items"] = normalizeJSONSchema(it)
-				case []any:
-					// If items provided as array, pick first and coerce
-					if len(it) > 0 {
-						first := normalizeJSONSchema(it[0])
-						if m, ok := first.(map[string]any); ok {
-							v["items"] = m
-						} else if s, ok := first.(string); ok {
-							v["items"] = map[string]any{"type": s}
-						} else {
-							v["items"] = map[string]any{"type": "string"}
-						}
-					} else {
-						v["items"] = map[string]any{"type": "string"}
-					}
-				case string:
-					v["items"] = map[string]any{"type": it}
-				default:
-					v["items"] = map[string]any{"type": "string"}
-				}
-			}
-		}
-		// Recurse nested object schemas
-		for k, val := range v {
-			if k == "properties" || k == "items" {
-				continue
-			}
-			v[k] = normalizeJSONSchema(val)
-		}
-		return v
-	case []any:
-		for i := range v {
-			v[i] = normalizeJSONSchema(v[i])
-		}
-		return v
-	default:
-		return node
-	}
-}
-
-// ensureObjectSchema normalizes an arbitrary map into a valid JSON Schema object
-// suitable for OpenAI tool function parameters. It guarantees the presence of
-// "type":"object" and at least an empty "properties" map. It also strips
-// unsupported or unnecessary keys that can cause rejections.
-func ensureObjectSchema(schema map[string]any) map[string]any {
-	if schema == nil {
-		return map[string]any{
-			"type":       "object",
-			"properties": map[string]any{},
-		}
-	}
-
-	// Remove noisy top-level keys often present in exported schemas
-	delete(schema, "$defs")
-	delete(schema, "$schema")
-	delete(schema, "$id")
-
-	// Deep normalization (arrays, nested properties)
-	schema = normalizeJSONSchema(schema).(map[string]any)
-
-	// Enforce object type schema expected by OpenAI for tool parameters
-	if t, ok := schema["type"].(string); !ok || t == "" {
-		schema["type"] = "object"
-	}
-
-	// Ensure properties exists; OpenAI requires an object schema with properties
-	if _, ok := schema["properties"]; !ok {
-		schema["properties"] = map[string]any{}
-	}
+// defaultToolTimeout bounds a tool call when the server config sets no timeout
+const defaultToolTimeout = 2 * time.Minute
 
-	return schema
+// serverConn is a live connection to an MCP server together with its listed tools.
+type serverConn struct {
+	session *mcp.ClientSession
+	proc    *os.Process // child process of a stdio server
+	tools   []*mcp.Tool
+	schemas []openai.ChatCompletionToolUnionParam
+	prompts []*mcp.Prompt
 }
 
 // RegisterServer connects to an MCP server, lists its tools and stores session/schema.
 // If a session with the same name exists, it is closed/replaced.
+// The server is then watched by a supervisor that pings it and reconnects on failure.
 func (m *Manager) RegisterServer(ctx context.Context, cfg *MCPServerConfig) error {
 	if cfg.Name == "" {
 		return fmt.Errorf("server name required")
 	}
-	if cfg.Endpoint == "" {
+	if cfg.Transport == TransportStdio {
+		if cfg.Command == "" {
+			return fmt.Errorf("command required")
+		}
+	} else if cfg.Endpoint == "" {
 		return fmt.Errorf("endpoint required")
 	}
 
+	conn, err := m.connectServer(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	m.installServer(cfg, conn)
+	m.startSupervisor(cfg)
+
+	slog.Info("registered MCP server", "server", cfg.Name, "tool_count", len(conn.schemas))
+	return nil
+}
+
+// connectServer opens a session to the server described by cfg and lists its tools.
+func (m *Manager) connectServer(ctx context.Context, cfg *MCPServerConfig) (*serverConn, error) {
+	// Apply per-server timeout on top of the caller's ctx
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+
 	// Create client and transport
-	client := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil)
+	name := cfg.Name
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(_ context.Context, req *mcp.ToolListChangedRequest) {
+			// don't block the session's read loop with the follow-up ListTools call
+			go m.refreshTools(name, req.Session)
+		},
+		PromptListChangedHandler: func(_ context.Context, req *mcp.PromptListChangedRequest) {
+			go m.refreshPrompts(name, req.Session)
+		},
+		CreateMessageHandler: m.samplingHandler(cfg),
+	})
 
-	// Build transport. Adjust headers/opts depending on your SDK version.
-	transport := &mcp.StreamableClientTransport{
-		Endpoint: cfg.Endpoint,
+	transport, cmd, err := buildTransport(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// Connect (use ctx from caller; it should include a timeout)
 	session, err := client.Connect(ctx, transport, nil)
+	// the transport has started the process (if any); it is only touched through proc from now on
+	var proc *os.Process
+	if cmd != nil {
+		proc = cmd.Process
+	}
 	if err != nil {
+		err = cfg.redactErr(err)
 		slog.Error("failed to connect", "server", cfg.Name, "error", err)
+		// Connect closes the session it started on most failures; make sure the process is gone
+		killProcess(cfg.Name, proc)
+		return nil, fmt.Errorf("failed to connect to %s: %w", cfg.Name, err)
 	}
 
-	// List tools
-	toolsResult, err := session.ListTools(ctx, nil)
+	// List tools (all pages), keeping only those the config exposes
+	tools, err := listAllTools(ctx, session)
 	if err != nil {
-		_ = session.Close()
-		return fmt.Errorf("failed to list tools on %s: %w", cfg.Name, err)
+		closeSession(cfg.Name, session, proc)
+		return nil, fmt.Errorf("failed to list tools on %s: %w", cfg.Name, cfg.redactErr(err))
 	}
+	tools = filterTools(cfg, tools)
 
 	// Build OpenAI schemas for this server
-	openAISchemas := make([]openai.ChatCompletionToolUnionParam, 0, len(toolsResult.Tools))
-	for _, tool := range toolsResult.Tools {
-		var params openai.FunctionParameters
-		if tool.InputSchema != nil {
-			b, err := json.Marshal(tool.InputSchema)
-			if err != nil {
-				slog.Warn("failed to marshal schema", "tool", tool.Name, "server", cfg.Name, "error", err)
-			} else {
-				var m map[string]any
-				if err := json.Unmarshal(b, &m); err != nil {
-					slog.Warn("failed to unmarshal schema", "tool", tool.Name, "server", cfg.Name, "error", err)
-				} else {
-					normalized := ensureObjectSchema(m)
-					params = openai.FunctionParameters(normalized)
-				}
-			}
+	openAISchemas := m.buildToolSchemas(cfg, tools)
+	openAISchemas = append(openAISchemas, m.resourceToolSchemas(cfg, session, tools)...)
+
+	return &serverConn{
+		session: session,
+		proc:    proc,
+		tools:   tools,
+		schemas: openAISchemas,
+		prompts: listAllPrompts(ctx, cfg.Name, session),
+	}, nil
+}
+
+// listAllTools lists the server's tools, following nextCursor until every page is read.
+func listAllTools(ctx context.Context, session *mcp.ClientSession) ([]*mcp.Tool, error) {
+	tools := make([]*mcp.Tool, 0)
+	params := &mcp.ListToolsParams{}
+	for {
+		res, err := session.ListTools(ctx, params)
+		if err != nil {
+			return nil, err
 		}
+		tools = append(tools, res.Tools...)
+		if res.NextCursor == "" {
+			return tools, nil
+		}
+		params.Cursor = res.NextCursor
+	}
+}
+
+// buildToolSchemas converts MCP tool descriptors into OpenAI function tool schemas,
+// applying the server's tool overrides (description, hidden and renamed params) and strict mode.
+func (m *Manager) buildToolSchemas(cfg *MCPServerConfig, tools []*mcp.Tool) []openai.ChatCompletionToolUnionParam {
+	serverName := cfg.Name
+
+	// servers don't promise an order; sort so the schemas (and hashed names) don't change between connects
+	sorted := make([]*mcp.Tool, len(tools))
+	copy(sorted, tools)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	openAISchemas := make([]openai.ChatCompletionToolUnionParam, 0, len(sorted))
+	for _, tool := range sorted {
+		params, strict := toolParameters(cfg, tool)
 
-		if params == nil {
-			params = openai.FunctionParameters(map[string]any{
-				"type":       "object",
-				"properties": map[string]any{},
-			})
+		description := tool.Description
+		if o := cfg.Tools.override(tool.Name); o != nil && o.Description != "" {
+			description = o.Description
 		}
 
 		fd := openai.FunctionDefinitionParam{
-			Name:        cfg.Name + "__" + tool.Name,
-			Description: openai.String(tool.Description),
-			Parameters:  params,
+			Name:        m.ToolName(serverName, tool.Name),
+			Description: openai.String(description),
+			Parameters:  openai.FunctionParameters(params),
+		}
+		if strict {
+			fd.Strict = openai.Bool(true)
 		}
 		openAISchemas = append(openAISchemas, openai.ChatCompletionFunctionTool(fd))
 	}
+	return openAISchemas
+}
 
+// installServer stores a fresh connection and marks the server healthy.
+func (m *Manager) installServer(cfg *MCPServerConfig, conn *serverConn) {
 	// Save into maps atomically: if an old session exists close it first
 	m.mu.Lock()
-	if oldSess, ok := m.sessions[cfg.Name]; ok {
-		_ = oldSess.Close()
-	}
-	m.sessions[cfg.Name] = session
-	m.tools[cfg.Name] = toolsResult.Tools
-	m.schemas[cfg.Name] = openAISchemas
-	// append to order if not already there
-	found := false
-	for _, n := range m.order {
-		if n == cfg.Name {
-			found = true
-			break
-		}
-	}
-	if !found {
-		m.order = append(m.order, cfg.Name)
+	closeSession(cfg.Name, m.sessions[cfg.Name], m.procs[cfg.Name])
+	delete(m.procs, cfg.Name)
+	if conn.proc != nil {
+		m.procs[cfg.Name] = conn.proc
 	}
+	m.sessions[cfg.Name] = conn.session
+	m.tools[cfg.Name] = conn.tools
+	m.schemas[cfg.Name] = conn.schemas
+	m.names.resetServer(cfg.Name, advertisedToolNames(conn.session, conn.tools))
+	m.prompts[cfg.Name] = conn.prompts
+	m.configs[cfg.Name] = cfg
+	m.healthy[cfg.Name] = true
+	m.addToOrder(cfg.Name)
 	m.mu.Unlock()
 
-	slog.Info("registered MCP server", "server", cfg.Name, "tool_count", len(openAISchemas))
-	return nil
+	// wake the supervisor as soon as the server drops the connection
+	go func() {
+		_ = conn.session.Wait()
+		if m.GetSession(cfg.Name) == conn.session {
+			m.markUnhealthy(cfg.Name, mcp.ErrConnectionClosed)
+		}
+	}()
+}
+
+// addToOrder appends name to the registration order if not already there. Caller holds m.mu.
+func (m *Manager) addToOrder(name string) {
+	for _, n := range m.order {
+		if n == name {
+			return
+		}
+	}
+	m.order = append(m.order, name)
 }
 
 // RegisterServers registers multiple servers concurrently with retry/backoff.
 // Returns first error encountered, if any. Successful registrations remain.
+// Servers that still fail are left to their supervisor, which keeps reconnecting in the background.
 func (m *Manager) RegisterServers(ctx context.Context, cfgs []MCPServerConfig) error {
-	g, ctx := errgroup.WithContext(ctx)
+	// plain group: one failing server must not cancel the others
+	var g errgroup.Group
 	for i := range cfgs {
 		cfg := cfgs[i]
 		g.Go(func() error {
@@ -258,29 +323,51 @@ func (m *Manager) RegisterServers(ctx context.Context, cfgs []MCPServerConfig) e
 						backoff *= 2
 						continue
 					case <-ctx.Done():
+						m.deferServer(&cfg, ctx.Err())
 						return ctx.Err()
 					}
 				}
 				slog.Info("server registered", "server", cfg.Name)
 				return nil
 			}
+			m.deferServer(&cfg, lastErr)
 			return lastErr
 		})
 	}
 	return g.Wait()
 }
 
+// deferServer records a server whose initial registration failed as unhealthy
+// and hands it to the supervisor for background reconnection.
+func (m *Manager) deferServer(cfg *MCPServerConfig, err error) {
+	m.mu.Lock()
+	m.configs[cfg.Name] = cfg
+	m.healthy[cfg.Name] = false
+	m.addToOrder(cfg.Name)
+	m.mu.Unlock()
+
+	slog.Warn("MCP server unavailable; reconnecting in background", "server", cfg.Name, "error", err)
+	m.startSupervisor(cfg)
+	m.wakeSupervisor(cfg.Name)
+}
+
 // UnregisterServer closes and removes the session and schema for the given server name.
 func (m *Manager) UnregisterServer(name string) error {
+	m.stopSupervisor(name)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if sess, ok := m.sessions[name]; ok {
-		_ = sess.Close()
-		delete(m.sessions, name)
-	}
+	closeSession(name, m.sessions[name], m.procs[name])
+	delete(m.sessions, name)
+	delete(m.procs, name)
 	delete(m.tools, name)
 	delete(m.schemas, name)
+	delete(m.prompts, name)
+	delete(m.configs, name)
+	delete(m.healthy, name)
+	delete(m.limits, name)
+	m.names.dropServer(name)
 	slog.Info("unregistered MCP server", "server", name)
 	return nil
 }
@@ -311,11 +398,35 @@ func (m *Manager) GetSchemas(name string) []openai.ChatCompletionToolUnionParam
 	return nil
 }
 
-// GetSchemas returns the OpenAI tool schemas for a given server name.
+// GetAllSchemas returns the OpenAI tool schemas of every healthy server.
+// Unhealthy servers are withheld until their supervisor reconnects them.
 func (m *Manager) GetAllSchemas() map[string][]openai.ChatCompletionToolUnionParam {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.schemas
+	out := make(map[string][]openai.ChatCompletionToolUnionParam, len(m.schemas))
+	for name, s := range m.schemas {
+		if !m.healthy[name] {
+			continue
+		}
+		out[name] = s
+	}
+	return out
+}
+
+// GetAllTools returns the OpenAI tool schemas of every healthy server as one list, in registration
+// order and with a stable order within each server, so requests are reproducible and the tools prefix
+// of the prompt stays cacheable.
+func (m *Manager) GetAllTools() []openai.ChatCompletionToolUnionParam {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]openai.ChatCompletionToolUnionParam, 0)
+	for _, name := range m.order {
+		if !m.healthy[name] {
+			continue
+		}
+		out = append(out, m.schemas[name]...)
+	}
+	return out
 }
 
 // ListServers returns the registered server names.
@@ -338,39 +449,150 @@ func (m *Manager) ListServersInOrder() []string {
 	return out
 }
 
-func (m *Manager) CallTool(ToolID string, ToolName string, args map[string]any) (string, error) {
-	split := strings.Split(ToolName, "__")
-	if len(split) != 2 {
-		return "", fmt.Errorf("invalid tool name format: %s", ToolName)
+// CallTool executes a "<server>__<tool>" call and converts the MCP result for the model.
+// The returned error is only set for failures to reach the tool; errors reported by the tool
+// itself come back as a ToolResult with IsError set.
+//
+// ctx is the caller's request context: cancelling it aborts the call and the SDK sends
+// notifications/cancelled to the server. A per-server/per-tool timeout is applied on top;
+// hitting it is reported to the model as a tool error rather than failing the turn.
+func (m *Manager) CallTool(ctx context.Context, ToolID string, ToolName string, args map[string]any) (*ToolResult, error) {
+	server, tool, ok := m.ResolveToolName(ToolName)
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", ToolName)
 	}
 
 	argsBytes, _ := json.Marshal(args)
 	slog.Info("calling tool", "tool", ToolName, "args", string(argsBytes))
 
-	toolResp, err := m.GetSession(split[0]).CallTool(context.Background(), &mcp.CallToolParams{
-		Name:      split[1],
+	session := m.GetSession(server)
+	if session == nil || !m.IsHealthy(server) {
+		err := fmt.Errorf("MCP server %s is unavailable; try again later", server)
+		slog.Error("tool call skipped", "tool", ToolName, "error", err)
+		return nil, err
+	}
+
+	if cfg := m.serverConfig(server); cfg != nil && !cfg.Tools.exposes(tool) {
+		err := fmt.Errorf("tool %s is not available on MCP server %s", tool, server)
+		slog.Error("tool call skipped", "tool", ToolName, "error", err)
+		return nil, err
+	}
+
+	if cfg := m.serverConfig(server); cfg != nil && cfg.StrictSchemas {
+		args, _ = dropNulls(args).(map[string]any)
+	}
+
+	// approval policy: may deny the call or replace the arguments the model chose
+	args, denied, err := m.approveToolCall(ctx, server, tool, args)
+	if err != nil {
+		return nil, err
+	}
+	if denied != "" {
+		return &ToolResult{Text: denied, IsError: true}, nil
+	}
+
+	// map renamed params back and inject hidden values (never logged or shown to the model)
+	if o := m.toolOverride(server, tool); o != nil {
+		args = o.applyArgs(args)
+	}
+
+	// wait for a free slot before the timeout starts so queued calls don't time out
+	release, err := m.acquireCallSlot(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	timeout := m.toolTimeout(server, tool)
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if m.isResourceTool(server, tool) {
+		text, err := m.callResourceTool(callCtx, server, tool, args)
+		if err != nil {
+			if timedOut(ctx, err) {
+				return timeoutResult(ToolName, timeout), nil
+			}
+			return nil, err
+		}
+		return &ToolResult{Text: truncateResult(text, m.maxResultChars(server))}, nil
+	}
+
+	toolResp, err := session.CallTool(callCtx, &mcp.CallToolParams{
+		Name:      tool,
 		Arguments: args,
 	})
 
 	if err != nil {
+		if timedOut(ctx, err) {
+			slog.Warn("tool call timed out", "tool", ToolName, "timeout", timeout)
+			return timeoutResult(ToolName, timeout), nil
+		}
 		slog.Error("tool call failed", "tool", ToolName, "error", err)
-		return err.Error(), err
+		if errors.Is(err, mcp.ErrConnectionClosed) {
+			m.markUnhealthy(server, err)
+		}
+		return nil, err
 	}
 
-	respBytes, err := json.Marshal(toolResp)
-	var respStr string
-	if err != nil {
-		slog.Error("failed to marshal tool response", "tool", ToolName, "error", err)
-		respStr = fmt.Sprintf("%+v", toolResp)
-	} else {
-		respStr = string(respBytes)
+	result := ConvertToolResult(toolResp, m.maxResultChars(server))
+	slog.Info("tool completed", "tool", ToolName, "is_error", result.IsError, "images", len(result.Images), "len", len(result.Text))
+	return result, nil
+}
+
+// serverConfig returns the config of a registered server, or nil.
+func (m *Manager) serverConfig(name string) *MCPServerConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.configs[name]
+}
+
+// toolTimeout returns the timeout for a tool: per-tool override, then per-server, then the default.
+func (m *Manager) toolTimeout(server, tool string) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cfg, ok := m.configs[server]
+	if !ok {
+		return defaultToolTimeout
+	}
+	if d, ok := cfg.ToolTimeouts[tool]; ok && d > 0 {
+		return d
+	}
+	if cfg.ToolTimeout > 0 {
+		return cfg.ToolTimeout
+	}
+	return defaultToolTimeout
+}
+
+// timedOut reports whether err comes from the tool timeout rather than from the caller cancelling.
+func timedOut(parent context.Context, err error) bool {
+	return parent.Err() == nil && errors.Is(err, context.DeadlineExceeded)
+}
+
+// timeoutResult tells the model the tool did not answer in time.
+func timeoutResult(toolName string, timeout time.Duration) *ToolResult {
+	return &ToolResult{
+		Text:    fmt.Sprintf("tool %s timed out after %s", toolName, timeout),
+		IsError: true,
 	}
+}
 
-	slog.Info("tool completed", "tool", ToolName, "respStr", respStr)
-	return respStr, nil
+// maxResultChars returns the result size cap configured for a server.
+func (m *Manager) maxResultChars(server string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if cfg, ok := m.configs[server]; ok && cfg.MaxResultChars > 0 {
+		return cfg.MaxResultChars
+	}
+	return defaultMaxResultChars
 }
 
 func (m *Manager) Close(ToolName string) {
-	split := strings.Split(ToolName, "__")
-	m.GetSession(split[0]).Close()
+	server, _, ok := m.ResolveToolName(ToolName)
+	if !ok {
+		return
+	}
+	if sess := m.GetSession(server); sess != nil {
+		_ = sess.Close()
+	}
 }
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PromptInfo is a prompt template published by a server.
+type PromptInfo struct {
+	Server string
+	Prompt *mcp.Prompt
+}
+
+// supportsPrompts reports whether the server advertised the prompts capability.
+func supportsPrompts(session *mcp.ClientSession) bool {
+	if session == nil || session.InitializeResult() == nil || session.InitializeResult().Capabilities == nil {
+		return false
+	}
+	return session.InitializeResult().Capabilities.Prompts != nil
+}
+
+// supportsCompletions reports whether the server advertised argument completion.
+func supportsCompletions(session *mcp.ClientSession) bool {
+	if session == nil || session.InitializeResult() == nil || session.InitializeResult().Capabilities == nil {
+		return false
+	}
+	return session.InitializeResult().Capabilities.Completions != nil
+}
+
+// listAllPrompts lists every prompt of the server (all pages). Failures are logged, not fatal:
+// prompts are optional and must not block tool registration.
+func listAllPrompts(ctx context.Context, server string, session *mcp.ClientSession) []*mcp.Prompt {
+	prompts := make([]*mcp.Prompt, 0)
+	if !supportsPrompts(session) {
+		return prompts
+	}
+	for p, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			slog.Warn("failed to list prompts", "server", server, "error", err)
+			break
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts
+}
+
+// refreshPrompts re-lists prompts after a notifications/prompts/list_changed.
+func (m *Manager) refreshPrompts(name string, session *mcp.ClientSession) {
+	if m.GetSession(name) != session {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	prompts := listAllPrompts(ctx, name, session)
+
+	m.mu.Lock()
+	if m.sessions[name] == session {
+		m.prompts[name] = prompts
+	}
+	m.mu.Unlock()
+	slog.Info("MCP prompts refreshed", "server", name, "prompt_count", len(prompts))
+}
+
+// ListAllPrompts returns the prompts of every healthy server, in registration order and sorted by name per server.
+func (m *Manager) ListAllPrompts() []PromptInfo {
+	out := make([]PromptInfo, 0)
+	for _, server := range m.ListServersInOrder() {
+		if !m.IsHealthy(server) {
+			continue
+		}
+		m.mu.RLock()
+		prompts := append([]*mcp.Prompt(nil), m.prompts[server]...)
+		m.mu.RUnlock()
+
+		sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+		for _, p := range prompts {
+			out = append(out, PromptInfo{Server: server, Prompt: p})
+		}
+	}
+	return out
+}
+
+// FindPrompt returns the named prompt of a server, or nil.
+func (m *Manager) FindPrompt(server, name string) *mcp.Prompt {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.prompts[server] {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// GetPrompt expands a prompt template with the given arguments.
+func (m *Manager) GetPrompt(ctx context.Context, server, name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	session := m.GetSession(server)
+	if session == nil || !m.IsHealthy(server) {
+		return nil, fmt.Errorf("MCP server %s is unavailable", server)
+	}
+	res, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: name, Arguments: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt %s on %s: %w", name, server, err)
+	}
+	slog.Info("prompt expanded", "server", server, "prompt", name, "messages", len(res.Messages))
+	return res, nil
+}
+
+// CompletePromptArgument asks the server for completion suggestions of a prompt argument.
+// Servers without the completions capability return no suggestions.
+func (m *Manager) CompletePromptArgument(ctx context.Context, server, prompt, arg, value string, known map[string]string) ([]string, error) {
+	session := m.GetSession(server)
+	if !supportsCompletions(session) {
+		return nil, nil
+	}
+	res, err := session.Complete(ctx, &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: prompt},
+		Argument: mcp.CompleteParamsArgument{Name: arg, Value: value},
+		Context:  &mcp.CompleteContext{Arguments: known},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete %s.%s on %s: %w", prompt, arg, server, err)
+	}
+	return res.Completion.Values, nil
+}
@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeyParts marks header / env names whose values must never reach the logs.
+var sensitiveKeyParts = []string{"authorization", "token", "key", "secret", "password", "cookie", "credential"}
+
+// BasicAuthConfig holds HTTP basic auth credentials for an MCP endpoint.
+type BasicAuthConfig struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// LogValue implements slog.LogValuer so the password is never logged.
+func (b *BasicAuthConfig) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("username", b.Username),
+		slog.String("password", redacted),
+	)
+}
+
+// isSensitiveKey reports whether a header or env var name looks like it carries a secret.
+func isSensitiveKey(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactMap returns a copy of m with sensitive values replaced.
+func redactMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if isSensitiveKey(k) {
+			v = redacted
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// LogValue implements slog.LogValuer so MCPServerConfig can be logged without leaking secrets.
+func (c MCPServerConfig) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("name", c.Name),
+		slog.String("transport", c.Transport),
+		slog.String("endpoint", c.redact(c.Endpoint)),
+		slog.Any("headers", redactMap(c.Headers)),
+	}
+	if c.APIKey != "" {
+		attrs = append(attrs, slog.String("api_key", redacted))
+	}
+	if c.BasicAuth != nil {
+		attrs = append(attrs, slog.Any("basic_auth", c.BasicAuth))
+	}
+	if c.Command != "" {
+		attrs = append(attrs,
+			slog.String("command", c.Command),
+			slog.Any("args", c.Args),
+			slog.Any("env", redactMap(c.Env)),
+		)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// secrets returns the literal secret values carried by the config, longest first.
+func (c *MCPServerConfig) secrets() []string {
+	out := make([]string, 0)
+	if c.APIKey != "" {
+		out = append(out, c.APIKey)
+	}
+	if c.BasicAuth != nil && c.BasicAuth.Password != "" {
+		out = append(out, c.BasicAuth.Password)
+	}
+	for k, v := range c.Headers {
+		if isSensitiveKey(k) && v != "" {
+			out = append(out, v)
+		}
+	}
+	for k, v := range c.Env {
+		if isSensitiveKey(k) && v != "" {
+			out = append(out, v)
+		}
+	}
+	if u, err := url.Parse(c.Endpoint); err == nil {
+		if p, ok := u.User.Password(); ok && p != "" {
+			out = append(out, p)
+		}
+		for k, vs := range u.Query() {
+			if !isSensitiveKey(k) {
+				continue
+			}
+			for _, v := range vs {
+				if v != "" {
+					out = append(out, v)
+				}
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// redact replaces every secret of the config found in s.
+func (c *MCPServerConfig) redact(s string) string {
+	for _, secret := range c.secrets() {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// redactedError wraps an error so that its message never contains the config's secrets.
+type redactedError struct {
+	err error
+	cfg *MCPServerConfig
+}
+
+func (e *redactedError) Error() string { return e.cfg.redact(e.err.Error()) }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactErr wraps err with redaction for cfg (nil stays nil).
+func (c *MCPServerConfig) redactErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err, cfg: c}
+}
@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openai/openai-go/v2"
+)
+
+// Synthetic tool names exposed for servers that offer resources
+const (
+	listResourcesTool = "list_resources"
+	readResourceTool  = "read_resource"
+)
+
+// ResourceInfo is a flattened view of a resource or resource template offered by a server.
+type ResourceInfo struct {
+	Server      string `json:"server"`
+	URI         string `json:"uri,omitempty"`
+	URITemplate string `json:"uriTemplate,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mimeType,omitempty"`
+}
+
+// supportsResources reports whether the server advertised the resources capability.
+func supportsResources(session *mcp.ClientSession) bool {
+	if session == nil || session.InitializeResult() == nil || session.InitializeResult().Capabilities == nil {
+		return false
+	}
+	return session.InitializeResult().Capabilities.Resources != nil
+}
+
+// hasTool reports whether the server itself publishes a tool with the given name.
+func hasTool(tools []*mcp.Tool, name string) bool {
+	for _, t := range tools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceToolSchemas returns the synthetic list/read resource tools for a server,
+// skipping any name the server already uses for a real tool. The server's include/exclude
+// filters apply to them as to real tools, so CallTool accepts exactly what is advertised.
+func (m *Manager) resourceToolSchemas(cfg *MCPServerConfig, session *mcp.ClientSession, tools []*mcp.Tool) []openai.ChatCompletionToolUnionParam {
+	out := make([]openai.ChatCompletionToolUnionParam, 0, 2)
+	if !supportsResources(session) {
+		return out
+	}
+	serverName := cfg.Name
+	listName := m.ToolName(serverName, listResourcesTool)
+	if !hasTool(tools, listResourcesTool) && cfg.Tools.exposes(listResourcesTool) {
+		out = append(out, openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+			Name:        listName,
+			Description: openai.String(fmt.Sprintf("List the resources and resource templates offered by the %s MCP server.", serverName)),
+			Parameters: openai.FunctionParameters{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+		}))
+	}
+	if !hasTool(tools, readResourceTool) && cfg.Tools.exposes(readResourceTool) {
+		out = append(out, openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+			Name:        m.ToolName(serverName, readResourceTool),
+			Description: openai.String(fmt.Sprintf("Read a resource from the %s MCP server by URI.", serverName)),
+			Parameters: openai.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"uri": map[string]any{
+						"type":        "string",
+						"description": "Resource URI as returned by " + listName,
+					},
+				},
+				"required": []string{"uri"},
+			},
+		}))
+	}
+	return out
+}
+
+// isResourceTool reports whether toolName on server is one of the synthetic resource tools.
+func (m *Manager) isResourceTool(server, toolName string) bool {
+	if toolName != listResourcesTool && toolName != readResourceTool {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return supportsResources(m.sessions[server]) && !hasTool(m.tools[server], toolName)
+}
+
+// callResourceTool executes a synthetic resource tool and returns its text result.
+func (m *Manager) callResourceTool(ctx context.Context, server, toolName string, args map[string]any) (string, error) {
+	switch toolName {
+	case listResourcesTool:
+		resources, err := m.ListResources(ctx, server)
+		if err != nil {
+			return err.Error(), err
+		}
+		b, err := json.Marshal(resources)
+		if err != nil {
+			return err.Error(), err
+		}
+		return string(b), nil
+	default:
+		uri, _ := args["uri"].(string)
+		if uri == "" {
+			err := fmt.Errorf("missing required argument: uri")
+			return err.Error(), err
+		}
+		text, err := m.ReadResourceText(ctx, server, uri)
+		if err != nil {
+			return err.Error(), err
+		}
+		return text, nil
+	}
+}
+
+// ListResources returns the resources and resource templates offered by a server (all pages).
+func (m *Manager) ListResources(ctx context.Context, server string) ([]ResourceInfo, error) {
+	session := m.GetSession(server)
+	if session == nil || !m.IsHealthy(server) {
+		return nil, fmt.Errorf("MCP server %s is unavailable", server)
+	}
+	if !supportsResources(session) {
+		return nil, fmt.Errorf("MCP server %s does not offer resources", server)
+	}
+
+	out := make([]ResourceInfo, 0)
+	for r, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources on %s: %w", server, err)
+		}
+		out = append(out, ResourceInfo{
+			Server:      server,
+			URI:         r.URI,
+			Name:        r.Name,
+			Description: r.Description,
+			MIMEType:    r.MIMEType,
+		})
+	}
+	for t, err := range session.ResourceTemplates(ctx, nil) {
+		if err != nil {
+			// templates are optional; keep the concrete resources
+			slog.Warn("failed to list resource templates", "server", server, "error", err)
+			break
+		}
+		out = append(out, ResourceInfo{
+			Server:      server,
+			URITemplate: t.URITemplate,
+			Name:        t.Name,
+			Description: t.Description,
+			MIMEType:    t.MIMEType,
+		})
+	}
+	return out, nil
+}
+
+// ListAllResources returns the resources of every healthy server that offers them, in registration order.
+func (m *Manager) ListAllResources(ctx context.Context) []ResourceInfo {
+	out := make([]ResourceInfo, 0)
+	for _, server := range m.ListServersInOrder() {
+		if !m.IsHealthy(server) || !supportsResources(m.GetSession(server)) {
+			continue
+		}
+		resources, err := m.ListResources(ctx, server)
+		if err != nil {
+			slog.Warn("failed to list resources", "server", server, "error", err)
+			continue
+		}
+		out = append(out, resources...)
+	}
+	return out
+}
+
+// ReadResource reads a resource by URI from the given server.
+func (m *Manager) ReadResource(ctx context.Context, server, uri string) (*mcp.ReadResourceResult, error) {
+	session := m.GetSession(server)
+	if session == nil || !m.IsHealthy(server) {
+		return nil, fmt.Errorf("MCP server %s is unavailable", server)
+	}
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource %s on %s: %w", uri, server, err)
+	}
+	return res, nil
+}
+
+// ReadResourceText reads a resource and flattens its contents into text for the model.
+// Binary contents are summarized instead of inlined.
+func (m *Manager) ReadResourceText(ctx context.Context, server, uri string) (string, error) {
+	res, err := m.ReadResource(ctx, server, uri)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, c := range res.Contents {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(ContentToText(&mcp.EmbeddedResource{Resource: c}))
+	}
+	slog.Info("resource read", "server", server, "uri", uri, "contents", len(res.Contents))
+	return sb.String(), nil
+}
@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openai/openai-go/v2"
+	"github.com/pavitra93/11-openai-chats/external/clients/llm"
+)
+
+// defaultSamplingMaxTokens is used when neither the request nor the server config sets a limit
+const defaultSamplingMaxTokens = 1024
+
+// SamplingConfig controls whether a server may ask the client for LLM completions (sampling/createMessage).
+type SamplingConfig struct {
+	Enabled         bool     `yaml:"enabled" json:"enabled"`
+	MaxRequests     int      `yaml:"max_requests" json:"max_requests"`         // quota per app run; 0 = unlimited
+	MaxTokens       int64    `yaml:"max_tokens" json:"max_tokens"`             // upper bound for the request's maxTokens
+	RequireApproval bool     `yaml:"require_approval" json:"require_approval"` // ask the user before every request
+	AllowedModels   []string `yaml:"allowed_models" json:"allowed_models"`     // models selectable via modelPreferences hints
+}
+
+// SamplingApprover asks the user whether a sampling request may run. It returns ctx's error when
+// the request is cancelled before the user answers.
+type SamplingApprover func(ctx context.Context, server string, params *mcp.CreateMessageParams) (bool, error)
+
+// Sampler routes MCP sampling requests through the chat completion provider.
+type Sampler struct {
+	Provider llm.Provider
+	Model    func() string // current chat model, read per request so /model switches apply
+	Approve  SamplingApprover
+
+	mu   sync.Mutex
+	used map[string]int // serverName -> sampling requests served
+}
+
+// NewSampler creates a Sampler using the given provider; model returns the default model of each request.
+func NewSampler(provider llm.Provider, model func() string, approve SamplingApprover) *Sampler {
+	return &Sampler{
+		Provider: provider,
+		Model:    model,
+		Approve:  approve,
+		used:     make(map[string]int),
+	}
+}
+
+// SetSampler enables sampling for servers registered afterwards whose config allows it.
+func (m *Manager) SetSampler(s *Sampler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sampler = s
+}
+
+// samplingHandler returns the CreateMessageHandler for cfg, or nil when sampling is disabled
+// (a nil handler keeps the sampling capability out of the client's initialize request).
+func (m *Manager) samplingHandler(cfg *MCPServerConfig) func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	m.mu.RLock()
+	sampler := m.sampler
+	m.mu.RUnlock()
+	if sampler == nil || cfg.Sampling == nil || !cfg.Sampling.Enabled {
+		return nil
+	}
+	name, policy := cfg.Name, *cfg.Sampling
+	return func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return sampler.createMessage(ctx, name, &policy, req.Params)
+	}
+}
+
+// checkQuota reports an error when the server has used up its quota.
+func (s *Sampler) checkQuota(server string, policy *SamplingConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quotaErr(server, policy)
+}
+
+// reserve counts a request against the server's quota.
+func (s *Sampler) reserve(server string, policy *SamplingConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.quotaErr(server, policy); err != nil {
+		return err
+	}
+	s.used[server]++
+	return nil
+}
+
+// quotaErr must be called with s.mu held.
+func (s *Sampler) quotaErr(server string, policy *SamplingConfig) error {
+	if policy.MaxRequests > 0 && s.used[server] >= policy.MaxRequests {
+		return fmt.Errorf("sampling quota exhausted for %s (%d requests)", server, policy.MaxRequests)
+	}
+	return nil
+}
+
+// selectModel honors modelPreferences hints restricted to the allowed models; otherwise the default model is used.
+func (s *Sampler) selectModel(policy *SamplingConfig, prefs *mcp.ModelPreferences) string {
+	if prefs == nil {
+		return s.Model()
+	}
+	for _, hint := range prefs.Hints {
+		if hint == nil || hint.Name == "" {
+			continue
+		}
+		for _, allowed := range policy.AllowedModels {
+			if strings.Contains(allowed, hint.Name) {
+				return allowed
+			}
+		}
+	}
+	return s.Model()
+}
+
+func (s *Sampler) createMessage(ctx context.Context, server string, policy *SamplingConfig, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty sampling request")
+	}
+	// don't ask the user about a request the quota rejects anyway
+	if err := s.checkQuota(server, policy); err != nil {
+		slog.Warn("sampling request rejected", "server", server, "error", err)
+		return nil, err
+	}
+	if policy.RequireApproval {
+		approved := false
+		if s.Approve != nil {
+			var err error
+			if approved, err = s.Approve(ctx, server, params); err != nil {
+				slog.Info("sampling approval cancelled", "server", server, "error", err)
+				return nil, fmt.Errorf("sampling approval cancelled: %w", err)
+			}
+		}
+		if !approved {
+			slog.Info("sampling request denied by user", "server", server)
+			return nil, fmt.Errorf("sampling request denied by user")
+		}
+	}
+	// only approved requests count against the quota
+	if err := s.reserve(server, policy); err != nil {
+		slog.Warn("sampling request rejected", "server", server, "error", err)
+		return nil, err
+	}
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(params.Messages)+1)
+	if params.SystemPrompt != "" {
+		messages = append(messages, openai.SystemMessage(params.SystemPrompt))
+	}
+	for _, msg := range params.Messages {
+		text := ContentToText(msg.Content)
+		if msg.Role == "assistant" {
+			messages = append(messages, openai.AssistantMessage(text))
+		} else {
+			messages = append(messages, openai.UserMessage(text))
+		}
+	}
+
+	maxTokens := params.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultSamplingMaxTokens
+	}
+	if policy.MaxTokens > 0 && maxTokens > policy.MaxTokens {
+		maxTokens = policy.MaxTokens
+	}
+
+	model := s.selectModel(policy, params.ModelPreferences)
+	req := openai.ChatCompletionNewParams{
+		Model:     model,
+		Messages:  messages,
+		MaxTokens: openai.Int(maxTokens),
+	}
+	if params.Temperature > 0 {
+		req.Temperature = openai.Float(params.Temperature)
+	}
+	if len(params.StopSequences) > 0 {
+		req.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: params.StopSequences}
+	}
+
+	slog.Info("sampling request", "server", server, "model", model, "messages", len(messages), "max_tokens", maxTokens)
+	resp, err := s.Provider.Complete(ctx, req)
+	if err != nil {
+		slog.Error("sampling completion failed", "server", server, "error", err)
+		return nil, fmt.Errorf("sampling completion failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("sampling completion returned no choices")
+	}
+
+	choice := resp.Choices[0]
+	stopReason := "endTurn"
+	if choice.FinishReason == "length" {
+		stopReason = "maxTokens"
+	}
+
+	slog.Info("sampling completed", "server", server, "model", resp.Model, "tokens", resp.Usage.TotalTokens)
+	return &mcp.CreateMessageResult{
+		Content:    &mcp.TextContent{Text: choice.Message.Content},
+		Model:      resp.Model,
+		Role:       "assistant",
+		StopReason: stopReason,
+	}, nil
+}
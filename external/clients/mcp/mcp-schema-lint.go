@@ -0,0 +1,43 @@
+package mcp
+
+// ToolSchemaLint is the schema translation result of one tool, for the lint command.
+type ToolSchemaLint struct {
+	Server   string `json:"server"`
+	Tool     string `json:"tool"`
+	Function string `json:"function"` // function name shown to the model
+	Strict   bool   `json:"strict"`   // strict_schemas is enabled for the server
+	SchemaReport
+}
+
+// Rejected reports whether OpenAI is expected to reject the tool's schema.
+func (l *ToolSchemaLint) Rejected() bool {
+	return len(l.Errors) > 0
+}
+
+// LintToolSchemas runs every exposed tool of the connected servers through the schema translator,
+// including the strict-mode conversion, in registration order.
+func (m *Manager) LintToolSchemas() []ToolSchemaLint {
+	out := make([]ToolSchemaLint, 0)
+	for _, server := range m.ListServersInOrder() {
+		cfg := m.serverConfig(server)
+		if cfg == nil {
+			continue
+		}
+		m.mu.RLock()
+		tools := m.tools[server]
+		m.mu.RUnlock()
+
+		for _, tool := range tools {
+			params, report := translateToolSchema(cfg, tool)
+			strictSchema(params, report)
+			out = append(out, ToolSchemaLint{
+				Server:       server,
+				Tool:         tool.Name,
+				Function:     m.ToolName(server, tool.Name),
+				Strict:       cfg.StrictSchemas,
+				SchemaReport: *report,
+			})
+		}
+	}
+	return out
+}
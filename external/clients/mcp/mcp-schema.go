@@ -0,0 +1,614 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SchemaReport records what the schema translator changed in a tool's input schema
+// and what it could not make OpenAI-compatible.
+type SchemaReport struct {
+	Transforms         []string `json:"transforms,omitempty"`          // changes applied, e.g. "inlined $ref #/$defs/Page at parent"
+	StrictIncompatible []string `json:"strict_incompatible,omitempty"` // why the schema cannot be sent in strict mode
+	Errors             []string `json:"errors,omitempty"`              // problems OpenAI is expected to reject
+}
+
+func (r *SchemaReport) transform(path, format string, a ...any) {
+	r.Transforms = append(r.Transforms, fmt.Sprintf(format, a...)+" at "+displayPath(path))
+}
+
+func (r *SchemaReport) strictIssue(path, format string, a ...any) {
+	r.StrictIncompatible = append(r.StrictIncompatible, fmt.Sprintf(format, a...)+" at "+displayPath(path))
+}
+
+func (r *SchemaReport) error(path, format string, a ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, a...)+" at "+displayPath(path))
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
+
+// schemaTranslator rewrites a JSON Schema into the subset OpenAI accepts for function parameters.
+// It never mutates its input.
+type schemaTranslator struct {
+	root   map[string]any
+	report *SchemaReport
+	refs   []string // $refs being inlined, for cycle detection
+}
+
+// schemaMetaKeys are dropped from translated schemas; definitions are inlined where referenced
+var schemaMetaKeys = map[string]bool{
+	"$schema": true, "$id": true, "$defs": true, "definitions": true, "$comment": true, "$anchor": true,
+}
+
+// conditionalKeys hold subschemas that OpenAI accepts outside strict mode
+var conditionalKeys = []string{"if", "then", "else", "contains"}
+
+// strictUnsupportedKeys are kept in non-strict schemas but rejected by strict mode
+var strictUnsupportedKeys = []string{"if", "then", "else", "contains", "dependentSchemas"}
+
+func (t *schemaTranslator) translate(node any, path string) any {
+	s, ok := node.(map[string]any)
+	if !ok {
+		return node
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		return t.inlineRef(ref, s, path)
+	}
+
+	out := make(map[string]any, len(s))
+	for k, v := range s {
+		if schemaMetaKeys[k] {
+			continue
+		}
+		out[k] = v
+	}
+
+	if props, ok := s["properties"].(map[string]any); ok {
+		translated := make(map[string]any, len(props))
+		for name, p := range props {
+			if _, ok := p.(map[string]any); !ok {
+				t.report.error(joinPath(path, name), "property schema is not an object")
+				p = map[string]any{}
+			}
+			translated[name] = t.translate(p, joinPath(path, name))
+		}
+		out["properties"] = translated
+	} else if _, exists := s["properties"]; exists {
+		t.report.error(path, "properties is not an object")
+		out["properties"] = map[string]any{}
+	}
+
+	if extra, ok := s["additionalProperties"].(map[string]any); ok {
+		out["additionalProperties"] = t.translate(extra, path+"{*}")
+	}
+
+	if patterns, ok := s["patternProperties"].(map[string]any); ok {
+		delete(out, "patternProperties")
+		if _, has := out["additionalProperties"]; !has {
+			keys := sortedKeys(patterns)
+			branches := make([]any, 0, len(keys))
+			for _, k := range keys {
+				branches = append(branches, t.translate(patterns[k], path+"{"+k+"}"))
+			}
+			if len(branches) == 1 {
+				out["additionalProperties"] = branches[0]
+			} else {
+				out["additionalProperties"] = map[string]any{"anyOf": branches}
+			}
+		}
+		t.report.transform(path, "replaced patternProperties with additionalProperties")
+	}
+
+	t.translateItems(s, out, path)
+
+	// conditional subschemas are passed on, translated; strict mode rejects them (see makeStrict)
+	for _, k := range conditionalKeys {
+		if sub, ok := s[k].(map[string]any); ok {
+			out[k] = t.translate(sub, path)
+		}
+	}
+	if deps, ok := s["dependentSchemas"].(map[string]any); ok {
+		translated := make(map[string]any, len(deps))
+		for name, d := range deps {
+			translated[name] = t.translate(d, path)
+		}
+		out["dependentSchemas"] = translated
+	}
+
+	if branches, ok := s["oneOf"].([]any); ok {
+		delete(out, "oneOf")
+		out["anyOf"] = append(t.translateList(branches, path), t.translateListOrNil(s["anyOf"], path)...)
+		t.report.transform(path, "converted oneOf to anyOf")
+	} else if branches, ok := s["anyOf"].([]any); ok {
+		out["anyOf"] = t.translateList(branches, path)
+	}
+
+	if branches, ok := s["allOf"].([]any); ok {
+		delete(out, "allOf")
+		for _, b := range t.translateList(branches, path) {
+			mergeSchema(out, b)
+		}
+		t.report.transform(path, "merged allOf")
+	}
+
+	if _, ok := s["not"]; ok {
+		delete(out, "not")
+		t.report.transform(path, "dropped unsupported not")
+	}
+
+	return out
+}
+
+// inlineRef replaces a local $ref with a translated copy of its target. Sibling keywords are
+// translated as a schema of their own and folded in: properties and required are merged with the
+// target's, other keywords (description, default, ...) replace the target's.
+func (t *schemaTranslator) inlineRef(ref string, s map[string]any, path string) any {
+	for _, open := range t.refs {
+		if open == ref {
+			t.report.transform(path, "cut recursive $ref %s", ref)
+			t.report.strictIssue(path, "recursive $ref %s replaced by a free-form object", ref)
+			out := map[string]any{"type": "object"}
+			if d, ok := s["description"]; ok {
+				out["description"] = d
+			}
+			return out
+		}
+	}
+
+	target, ok := resolveLocalRef(t.root, ref)
+	if !ok {
+		t.report.error(path, "unresolvable $ref %s", ref)
+		return map[string]any{}
+	}
+
+	t.refs = append(t.refs, ref)
+	inlined := t.translate(target, path)
+	t.refs = t.refs[:len(t.refs)-1]
+	t.report.transform(path, "inlined $ref %s", ref)
+
+	out, ok := inlined.(map[string]any)
+	if !ok {
+		return inlined
+	}
+	siblings := make(map[string]any, len(s))
+	for k, v := range s {
+		if k != "$ref" {
+			siblings[k] = v
+		}
+	}
+	translated, _ := t.translate(siblings, path).(map[string]any)
+	for k, v := range translated {
+		switch k {
+		case "properties", "required":
+			mergeSchema(out, map[string]any{k: v})
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// translateItems normalizes array items: tuples become a single items schema, missing items default to strings.
+func (t *schemaTranslator) translateItems(s, out map[string]any, path string) {
+	tuple, isTuple := s["prefixItems"].([]any)
+	if list, ok := s["items"].([]any); ok {
+		tuple, isTuple = list, true
+	}
+	if isTuple {
+		delete(out, "prefixItems")
+		branches := dedupeSchemas(t.translateList(tuple, path+"[]"))
+		switch len(branches) {
+		case 0:
+			out["items"] = map[string]any{"type": "string"}
+		case 1:
+			out["items"] = branches[0]
+		default:
+			out["items"] = map[string]any{"anyOf": branches}
+		}
+		if _, ok := s["minItems"]; !ok && len(tuple) > 0 {
+			out["minItems"] = len(tuple)
+		}
+		t.report.transform(path, "converted tuple items to a single items schema")
+		return
+	}
+
+	switch items := s["items"].(type) {
+	case map[string]any:
+		out["items"] = t.translate(items, path+"[]")
+	case string:
+		out["items"] = map[string]any{"type": items}
+		t.report.transform(path, "converted string items to a schema")
+	case nil:
+		if typ, _ := s["type"].(string); typ == "array" {
+			out["items"] = map[string]any{"type": "string"}
+			t.report.transform(path, "added missing array items")
+		}
+	default:
+		out["items"] = map[string]any{"type": "string"}
+		t.report.transform(path, "replaced invalid array items")
+	}
+}
+
+func (t *schemaTranslator) translateList(list []any, path string) []any {
+	out := make([]any, 0, len(list))
+	for _, b := range list {
+		out = append(out, t.translate(b, path))
+	}
+	return out
+}
+
+func (t *schemaTranslator) translateListOrNil(v any, path string) []any {
+	list, _ := v.([]any)
+	return t.translateList(list, path)
+}
+
+// resolveLocalRef follows a local JSON pointer such as "#/$defs/Page" or "#/definitions/Page".
+func resolveLocalRef(root map[string]any, ref string) (map[string]any, bool) {
+	if ref == "#" {
+		return root, true
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var node any = root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		node = obj[part]
+	}
+	s, ok := node.(map[string]any)
+	return s, ok
+}
+
+// mergeSchema folds src into dst: properties and required are combined, other keywords are kept from dst.
+func mergeSchema(dst map[string]any, src any) {
+	s, ok := src.(map[string]any)
+	if !ok {
+		return
+	}
+	for k, v := range s {
+		switch k {
+		case "properties":
+			props, _ := dst["properties"].(map[string]any)
+			merged := make(map[string]any, len(props))
+			for name, p := range props {
+				merged[name] = p
+			}
+			if sp, ok := v.(map[string]any); ok {
+				for name, p := range sp {
+					merged[name] = p
+				}
+			}
+			dst["properties"] = merged
+		case "required":
+			dst["required"] = unionStrings(dst["required"], v)
+		default:
+			if _, exists := dst[k]; !exists {
+				dst[k] = v
+			}
+		}
+	}
+}
+
+// mergeObjectBranches turns a root anyOf of object schemas into one object schema. The root's own
+// properties and required list are kept; branch properties are added, and of the branch requirements
+// only those shared by every branch stay required.
+func mergeObjectBranches(schema map[string]any, branches []any) {
+	props := make(map[string]any)
+	if rp, ok := schema["properties"].(map[string]any); ok {
+		for name, p := range rp {
+			props[name] = p
+		}
+	}
+	var required map[string]int
+	for _, b := range branches {
+		bs, ok := b.(map[string]any)
+		if !ok {
+			continue
+		}
+		if bp, ok := bs["properties"].(map[string]any); ok {
+			for name, p := range bp {
+				if _, seen := props[name]; !seen {
+					props[name] = p
+				}
+			}
+		}
+		counts := make(map[string]int)
+		for _, r := range toStrings(bs["required"]) {
+			counts[r]++
+		}
+		if required == nil {
+			required = counts
+			continue
+		}
+		for r := range required {
+			if counts[r] == 0 {
+				delete(required, r)
+			}
+		}
+	}
+	schema["type"] = "object"
+	schema["properties"] = props
+	keep := unionStrings(schema["required"], toAny(sortedKeys(required)))
+	if len(keep) > 0 {
+		schema["required"] = keep
+	} else {
+		delete(schema, "required")
+	}
+}
+
+// makeStrict rewrites a translated schema for OpenAI strict mode: every object gets
+// additionalProperties:false and lists all properties as required; optional ones become nullable.
+func makeStrict(node any, path string, report *SchemaReport) {
+	s, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if props, ok := s["properties"].(map[string]any); ok || s["type"] == "object" {
+		if extra, ok := s["additionalProperties"]; ok && extra != false {
+			report.strictIssue(path, "object allows additional properties")
+		}
+		if len(props) == 0 && path != "" {
+			report.strictIssue(path, "free-form object without properties")
+		}
+		required := make(map[string]bool)
+		for _, r := range toStrings(s["required"]) {
+			required[r] = true
+		}
+		all := make([]any, 0, len(props))
+		for _, name := range sortedKeys(props) {
+			makeStrict(props[name], joinPath(path, name), report)
+			if !required[name] {
+				props[name] = nullable(props[name])
+			}
+			all = append(all, name)
+		}
+		s["additionalProperties"] = false
+		s["required"] = all
+	}
+
+	for _, k := range strictUnsupportedKeys {
+		if _, ok := s[k]; ok {
+			report.strictIssue(path, "unsupported keyword %s", k)
+		}
+	}
+
+	if items, ok := s["items"].(map[string]any); ok {
+		makeStrict(items, path+"[]", report)
+	}
+	if branches, ok := s["anyOf"].([]any); ok {
+		if path == "" {
+			report.strictIssue(path, "anyOf at the root")
+		}
+		for _, b := range branches {
+			makeStrict(b, path, report)
+		}
+	}
+}
+
+// nullable lets a schema also accept null.
+func nullable(node any) any {
+	s, ok := node.(map[string]any)
+	if !ok {
+		return node
+	}
+	switch t := s["type"].(type) {
+	case string:
+		if t != "null" {
+			s["type"] = []any{t, "null"}
+		}
+		return s
+	case []any:
+		for _, x := range t {
+			if x == "null" {
+				return s
+			}
+		}
+		s["type"] = append(t, "null")
+		return s
+	}
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
+
+// dedupeSchemas drops structurally identical schemas, keeping the first occurrence.
+func dedupeSchemas(list []any) []any {
+	out := make([]any, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, s := range list {
+		key := fmt.Sprintf("%v", s)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, s)
+	}
+	return out
+}
+
+func toStrings(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, x := range list {
+		if s, ok := x.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func toAny(list []string) []any {
+	out := make([]any, len(list))
+	for i, s := range list {
+		out[i] = s
+	}
+	return out
+}
+
+func unionStrings(a, b any) []any {
+	seen := make(map[string]bool)
+	out := make([]any, 0)
+	for _, s := range append(toStrings(a), toStrings(b)...) {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ensureObjectSchema translates a tool input schema into a JSON Schema object suitable for
+// OpenAI function parameters: $refs are inlined, unsupported constructs are converted, and the
+// result always has "type":"object" and a properties map.
+func ensureObjectSchema(schema map[string]any) (map[string]any, *SchemaReport) {
+	report := &SchemaReport{}
+	if schema == nil {
+		return map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}, report
+	}
+
+	t := &schemaTranslator{root: schema, report: report}
+	out := t.translate(schema, "").(map[string]any)
+
+	// OpenAI needs a single object at the root
+	if branches, ok := out["anyOf"].([]any); ok && (out["type"] == nil || out["type"] == "object") {
+		delete(out, "anyOf")
+		mergeObjectBranches(out, branches)
+		report.transform("", "merged root anyOf into one object")
+	}
+	switch typ := out["type"].(type) {
+	case nil:
+		out["type"] = "object"
+	case string:
+		if typ == "" {
+			out["type"] = "object"
+		} else if typ != "object" {
+			report.error("", "parameters must be an object schema, got type %q", typ)
+		}
+	default:
+		report.error("", "parameters must be an object schema, got type %v", typ)
+	}
+	if _, ok := out["properties"]; !ok {
+		out["properties"] = map[string]any{}
+	}
+	return out, report
+}
+
+// strictSchema returns a strict-mode copy of a translated schema, or nil when the schema
+// can't be expressed in strict mode (the reasons are added to report).
+func strictSchema(schema map[string]any, report *SchemaReport) map[string]any {
+	strict := cloneSchema(schema).(map[string]any)
+	makeStrict(strict, "", report)
+	if len(report.StrictIncompatible) > 0 {
+		return nil
+	}
+	return strict
+}
+
+// cloneSchema deep-copies a decoded JSON value.
+func cloneSchema(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, x := range v {
+			out[k] = cloneSchema(x)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = cloneSchema(x)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// dropNulls removes null members from objects. Strict schemas make optional params nullable,
+// and servers expect such params to be absent rather than null.
+func dropNulls(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, x := range v {
+			if x != nil {
+				out[k] = dropNulls(x)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = dropNulls(x)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// toolParameters builds the OpenAI parameters schema of a tool and reports whether it is sent in strict mode.
+func toolParameters(cfg *MCPServerConfig, tool *mcp.Tool) (map[string]any, bool) {
+	params, report := translateToolSchema(cfg, tool)
+	for _, e := range report.Errors {
+		slog.Warn("tool schema may be rejected by OpenAI", "server", cfg.Name, "tool", tool.Name, "problem", e)
+	}
+	if len(report.Transforms) > 0 {
+		slog.Debug("tool schema translated", "server", cfg.Name, "tool", tool.Name, "transforms", report.Transforms)
+	}
+	if !cfg.StrictSchemas {
+		return params, false
+	}
+	if strict := strictSchema(params, report); strict != nil {
+		return strict, true
+	}
+	slog.Warn("tool schema not strict-mode compatible; sending it non-strict", "server", cfg.Name, "tool", tool.Name, "reasons", report.StrictIncompatible)
+	return params, false
+}
+
+// translateToolSchema converts a tool's InputSchema and applies the server's override for the tool.
+func translateToolSchema(cfg *MCPServerConfig, tool *mcp.Tool) (map[string]any, *SchemaReport) {
+	var raw map[string]any
+	if tool.InputSchema != nil {
+		b, err := json.Marshal(tool.InputSchema)
+		if err == nil {
+			err = json.Unmarshal(b, &raw)
+		}
+		if err != nil {
+			slog.Warn("failed to decode tool schema", "tool", tool.Name, "server", cfg.Name, "error", err)
+			raw = nil
+		}
+	}
+
+	params, report := ensureObjectSchema(raw)
+	if o := cfg.Tools.override(tool.Name); o != nil {
+		o.applySchema(params)
+	}
+	return params, report
+}
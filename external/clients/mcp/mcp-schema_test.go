@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeSchema(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var out map[string]any
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		t.Fatalf("bad test schema: %v", err)
+	}
+	return out
+}
+
+func TestEnsureObjectSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		want       string
+		transforms []string // substrings expected in the report
+		errors     []string
+	}{
+		{
+			name: "nil schema",
+			in:   `null`,
+			want: `{"type":"object","properties":{}}`,
+		},
+		{
+			name: "missing type and properties",
+			in:   `{"description":"no params"}`,
+			want: `{"type":"object","properties":{},"description":"no params"}`,
+		},
+		{
+			name: "ref inlined with sibling keywords",
+			in: `{"type":"object","properties":{"page":{"$ref":"#/$defs/Page","description":"which page"}},
+				"$defs":{"Page":{"type":"object","properties":{"id":{"type":"string"}},"description":"a page"}}}`,
+			want:       `{"type":"object","properties":{"page":{"type":"object","properties":{"id":{"type":"string"}},"description":"which page"}}}`,
+			transforms: []string{"inlined $ref #/$defs/Page at page"},
+		},
+		{
+			name: "definitions and escaped pointer",
+			in:   `{"type":"object","properties":{"a":{"$ref":"#/definitions/a~1b"}},"definitions":{"a/b":{"type":"integer"}}}`,
+			want: `{"type":"object","properties":{"a":{"type":"integer"}}}`,
+		},
+		{
+			name: "recursive ref is cut",
+			in: `{"type":"object","properties":{"root":{"$ref":"#/$defs/Node"}},
+				"$defs":{"Node":{"type":"object","properties":{"name":{"type":"string"},"child":{"$ref":"#/$defs/Node","description":"next"}}}}}`,
+			want:       `{"type":"object","properties":{"root":{"type":"object","properties":{"name":{"type":"string"},"child":{"type":"object","description":"next"}}}}}`,
+			transforms: []string{"cut recursive $ref #/$defs/Node at root.child"},
+		},
+		{
+			name:       "self reference to the root",
+			in:         `{"type":"object","properties":{"parent":{"$ref":"#"}}}`,
+			want:       `{"type":"object","properties":{"parent":{"type":"object","properties":{"parent":{"type":"object"}}}}}`,
+			transforms: []string{"cut recursive $ref # at parent.parent"},
+		},
+		{
+			name:   "unresolvable ref",
+			in:     `{"type":"object","properties":{"x":{"$ref":"#/$defs/Missing"}}}`,
+			want:   `{"type":"object","properties":{"x":{}}}`,
+			errors: []string{"unresolvable $ref #/$defs/Missing at x"},
+		},
+		{
+			name: "allOf merged",
+			in: `{"allOf":[
+				{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]},
+				{"properties":{"b":{"$ref":"#/$defs/B"}},"required":["a","b"],"description":"second"}],
+				"$defs":{"B":{"type":"number"}}}`,
+			want:       `{"type":"object","properties":{"a":{"type":"string"},"b":{"type":"number"}},"required":["a","b"],"description":"second"}`,
+			transforms: []string{"merged allOf at <root>"},
+		},
+		{
+			name:       "oneOf becomes anyOf",
+			in:         `{"type":"object","properties":{"v":{"oneOf":[{"type":"string"},{"type":"integer"}]}}}`,
+			want:       `{"type":"object","properties":{"v":{"anyOf":[{"type":"string"},{"type":"integer"}]}}}`,
+			transforms: []string{"converted oneOf to anyOf at v"},
+		},
+		{
+			name: "root anyOf merged into one object",
+			in: `{"anyOf":[
+				{"type":"object","properties":{"id":{"type":"string"},"url":{"type":"string"}},"required":["id","url"]},
+				{"type":"object","properties":{"id":{"type":"string"},"name":{"type":"string"}},"required":["id"]}]}`,
+			want:       `{"type":"object","properties":{"id":{"type":"string"},"url":{"type":"string"},"name":{"type":"string"}},"required":["id"]}`,
+			transforms: []string{"merged root anyOf into one object"},
+		},
+		{
+			name: "root anyOf keeps root properties",
+			in: `{"type":"object","properties":{"a":{"type":"string"},"b":{"type":"string"}},
+				"anyOf":[{"required":["a"]},{"required":["b"]}]}`,
+			want:       `{"type":"object","properties":{"a":{"type":"string"},"b":{"type":"string"}}}`,
+			transforms: []string{"merged root anyOf into one object"},
+		},
+		{
+			name: "root anyOf keeps root required",
+			in: `{"type":"object","properties":{"q":{"type":"string"}},"required":["q"],
+				"anyOf":[{"properties":{"a":{"type":"string"}},"required":["a"]},{"properties":{"b":{"type":"string"}},"required":["b"]}]}`,
+			want: `{"type":"object","properties":{"q":{"type":"string"},"a":{"type":"string"},"b":{"type":"string"}},"required":["q"]}`,
+		},
+		{
+			name: "ref siblings merged into target",
+			in: `{"type":"object","properties":{"page":{"$ref":"#/$defs/Page","properties":{"size":{"$ref":"#/$defs/N"}},"required":["size"]}},
+				"$defs":{"Page":{"type":"object","properties":{"id":{"type":"string"}},"required":["id"]},"N":{"type":"integer"}}}`,
+			want: `{"type":"object","properties":{"page":{"type":"object","properties":{"id":{"type":"string"},"size":{"type":"integer"}},"required":["id","size"]}}}`,
+		},
+		{
+			name: "conditional subschemas translated",
+			in: `{"type":"object","properties":{"tags":{"type":"array","items":{"type":"string"},"contains":{"$ref":"#/$defs/T"}}},
+				"if":{"properties":{"tags":{"oneOf":[{"type":"array"},{"type":"null"}]}}},"then":{"required":["tags"]},
+				"dependentSchemas":{"tags":{"$ref":"#/$defs/R"}},
+				"$defs":{"T":{"type":"string","enum":["x"]},"R":{"required":["tags"]}}}`,
+			want: `{"type":"object","properties":{"tags":{"type":"array","items":{"type":"string"},"contains":{"type":"string","enum":["x"]}}},
+				"if":{"properties":{"tags":{"anyOf":[{"type":"array","items":{"type":"string"}},{"type":"null"}]}}},"then":{"required":["tags"]},
+				"dependentSchemas":{"tags":{"required":["tags"]}}}`,
+			transforms: []string{"inlined $ref #/$defs/T at tags", "converted oneOf to anyOf"},
+		},
+		{
+			name:       "tuple items",
+			in:         `{"type":"object","properties":{"point":{"type":"array","prefixItems":[{"type":"number"},{"type":"number"}]}}}`,
+			want:       `{"type":"object","properties":{"point":{"type":"array","items":{"type":"number"},"minItems":2}}}`,
+			transforms: []string{"converted tuple items to a single items schema at point"},
+		},
+		{
+			name:   "non-object root",
+			in:     `{"type":"string"}`,
+			want:   `{"type":"string","properties":{}}`,
+			errors: []string{`parameters must be an object schema, got type "string"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := decodeSchema(t, tt.in)
+			before, _ := json.Marshal(in)
+
+			got, report := ensureObjectSchema(in)
+
+			if after, _ := json.Marshal(in); string(after) != string(before) {
+				t.Errorf("input schema was mutated: %s", after)
+			}
+			if want := decodeSchema(t, tt.want); !reflect.DeepEqual(normalize(t, got), want) {
+				b, _ := json.Marshal(got)
+				t.Errorf("schema = %s\nwant     %s", b, tt.want)
+			}
+			checkReport(t, "transforms", report.Transforms, tt.transforms)
+			checkReport(t, "errors", report.Errors, tt.errors)
+			if tt.errors == nil && len(report.Errors) > 0 {
+				t.Errorf("unexpected errors %v", report.Errors)
+			}
+		})
+	}
+}
+
+func TestStrictSchema(t *testing.T) {
+	t.Run("optional params become nullable", func(t *testing.T) {
+		in := decodeSchema(t, `{"type":"object","properties":{"q":{"type":"string"},"page":{"type":"integer"},
+			"opts":{"type":"object","properties":{"deep":{"type":"boolean"}}}},"required":["q"]}`)
+		schema, report := ensureObjectSchema(in)
+		strict := strictSchema(schema, report)
+		if strict == nil {
+			t.Fatalf("strict schema rejected: %v", report.StrictIncompatible)
+		}
+		want := decodeSchema(t, `{"type":"object","additionalProperties":false,"required":["opts","page","q"],"properties":{
+			"q":{"type":"string"},
+			"page":{"type":["integer","null"]},
+			"opts":{"type":["object","null"],"additionalProperties":false,"required":["deep"],"properties":{"deep":{"type":["boolean","null"]}}}}}`)
+		if !reflect.DeepEqual(normalize(t, strict), want) {
+			b, _ := json.Marshal(strict)
+			t.Errorf("strict schema = %s", b)
+		}
+		if _, ok := schema["additionalProperties"]; ok {
+			t.Errorf("strictSchema modified the translated schema")
+		}
+	})
+
+	t.Run("recursive ref is not strict-compatible", func(t *testing.T) {
+		in := decodeSchema(t, `{"type":"object","properties":{"node":{"$ref":"#/$defs/N"}},
+			"$defs":{"N":{"type":"object","properties":{"next":{"$ref":"#/$defs/N"}}}}}`)
+		schema, report := ensureObjectSchema(in)
+		if strict := strictSchema(schema, report); strict != nil {
+			t.Fatalf("expected no strict schema")
+		}
+		checkReport(t, "strict issues", report.StrictIncompatible, []string{"recursive $ref #/$defs/N replaced by a free-form object at node.next"})
+	})
+
+	t.Run("conditional keywords are not strict-compatible", func(t *testing.T) {
+		schema, report := ensureObjectSchema(decodeSchema(t, `{"type":"object","properties":{
+			"tags":{"type":"array","items":{"type":"string"},"contains":{"const":"x"}}},
+			"if":{"required":["tags"]},"then":{"properties":{"tags":{"minItems":1}}}}`))
+		if strict := strictSchema(schema, report); strict != nil {
+			t.Fatalf("expected no strict schema")
+		}
+		checkReport(t, "strict issues", report.StrictIncompatible, []string{
+			"unsupported keyword if at <root>", "unsupported keyword then at <root>", "unsupported keyword contains at tags"})
+	})
+
+	t.Run("free-form object", func(t *testing.T) {
+		schema, report := ensureObjectSchema(decodeSchema(t, `{"type":"object","properties":{"meta":{"type":"object","additionalProperties":true}}}`))
+		if strict := strictSchema(schema, report); strict != nil {
+			t.Fatalf("expected no strict schema")
+		}
+		checkReport(t, "strict issues", report.StrictIncompatible, []string{"object allows additional properties at meta"})
+	})
+}
+
+// normalize round-trips a schema through JSON so Go types match decoded test fixtures.
+func normalize(t *testing.T, schema map[string]any) map[string]any {
+	t.Helper()
+	b, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return decodeSchema(t, string(b))
+}
+
+// checkReport asserts that every wanted entry is a substring of some report entry.
+func checkReport(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if strings.Contains(g, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("%s %v: missing %q", kind, got, w)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultHealthInterval   = 30 * time.Second
+	defaultReconnectTimeout = 15 * time.Second
+	pingTimeout             = 5 * time.Second
+	reconnectBaseDelay      = 1 * time.Second
+	reconnectMaxDelay       = 60 * time.Second
+)
+
+// supervisor pings a single server and reconnects it when it stops responding.
+type supervisor struct {
+	cancel context.CancelFunc
+	wake   chan struct{}
+}
+
+// startSupervisor starts the health supervisor for cfg.Name unless one is already running.
+func (m *Manager) startSupervisor(cfg *MCPServerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.supervisors[cfg.Name]; ok {
+		return
+	}
+
+	// not derived from the registration ctx: supervisors live until UnregisterServer
+	ctx, cancel := context.WithCancel(context.Background())
+	sup := &supervisor{cancel: cancel, wake: make(chan struct{}, 1)}
+	m.supervisors[cfg.Name] = sup
+	go m.supervise(ctx, cfg.Name, sup.wake)
+}
+
+// stopSupervisor stops the health supervisor of the given server, if any.
+func (m *Manager) stopSupervisor(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sup, ok := m.supervisors[name]; ok {
+		sup.cancel()
+		delete(m.supervisors, name)
+	}
+}
+
+// wakeSupervisor asks the supervisor to check the server right away (non-blocking).
+func (m *Manager) wakeSupervisor(name string) {
+	m.mu.RLock()
+	sup, ok := m.supervisors[name]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+	select {
+	case sup.wake <- struct{}{}:
+	default:
+	}
+}
+
+// IsHealthy reports whether the server is connected and answered its last health check.
+func (m *Manager) IsHealthy(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.healthy[name]
+}
+
+// markUnhealthy hides the server's tools from the model and triggers a reconnect.
+func (m *Manager) markUnhealthy(name string, err error) {
+	m.mu.Lock()
+	wasHealthy := m.healthy[name]
+	if _, ok := m.configs[name]; ok {
+		m.healthy[name] = false
+	}
+	m.mu.Unlock()
+
+	if wasHealthy {
+		slog.Warn("MCP server marked unhealthy", "server", name, "error", err)
+	}
+	m.wakeSupervisor(name)
+}
+
+// supervise is the per-server health loop: ping on every tick (or wake-up),
+// and reconnect with exponential backoff once the server is unhealthy.
+func (m *Manager) supervise(ctx context.Context, name string, wake <-chan struct{}) {
+	m.mu.RLock()
+	cfg := m.configs[name]
+	m.mu.RUnlock()
+
+	interval := defaultHealthInterval
+	if cfg != nil && cfg.HealthInterval > 0 {
+		interval = cfg.HealthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+
+		if m.IsHealthy(name) {
+			sess := m.GetSession(name)
+			if sess == nil {
+				continue
+			}
+			pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+			err := sess.Ping(pingCtx, nil)
+			cancel()
+			if err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			m.markUnhealthy(name, err)
+		}
+
+		m.reconnect(ctx, name)
+	}
+}
+
+// reconnect re-establishes the session (re-listing tools) until it succeeds or ctx is cancelled.
+func (m *Manager) reconnect(ctx context.Context, name string) {
+	delay := reconnectBaseDelay
+	for attempt := 1; ; attempt++ {
+		m.mu.RLock()
+		cfg := m.configs[name]
+		m.mu.RUnlock()
+		if cfg == nil {
+			return
+		}
+
+		connectCtx := ctx
+		cancel := context.CancelFunc(func() {})
+		if cfg.ConnectTimeout <= 0 {
+			connectCtx, cancel = context.WithTimeout(ctx, defaultReconnectTimeout)
+		}
+		conn, err := m.connectServer(connectCtx, cfg)
+		cancel()
+		if err == nil {
+			if ctx.Err() != nil {
+				// unregistered while connecting
+				closeSession(name, conn.session, conn.proc)
+				return
+			}
+			m.installServer(cfg, conn)
+			slog.Info("MCP server reconnected", "server", name, "attempt", attempt, "tool_count", len(conn.schemas))
+			return
+		}
+
+		slog.Warn("MCP server reconnect failed", "server", name, "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolsConfig selects and reshapes the tools a server exposes to the model.
+// Patterns are globs matched against MCP tool names ("API-get-*").
+type ToolsConfig struct {
+	Include   []string                `yaml:"include" json:"include"`     // only expose matching tools (empty = all)
+	Exclude   []string                `yaml:"exclude" json:"exclude"`     // never expose matching tools
+	Overrides map[string]ToolOverride `yaml:"overrides" json:"overrides"` // keyed by tool name or pattern
+}
+
+// ToolOverride changes how a single tool is presented to the model.
+type ToolOverride struct {
+	Description string            `yaml:"description" json:"description"` // replaces the server's description
+	Hidden      map[string]any    `yaml:"hidden" json:"hidden"`           // params removed from the schema; the value is injected on every call
+	Rename      map[string]string `yaml:"rename" json:"rename"`           // server param name -> name shown to the model
+}
+
+// validate checks patterns and that renames don't collide.
+func (c *ToolsConfig) validate() error {
+	for _, pattern := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	for key, o := range c.Overrides {
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("invalid tool override pattern %q: %w", key, err)
+		}
+		seen := make(map[string]string, len(o.Rename))
+		for from, to := range o.Rename {
+			if to == "" {
+				return fmt.Errorf("tool override %q: empty new name for param %q", key, from)
+			}
+			if prev, ok := seen[to]; ok {
+				return fmt.Errorf("tool override %q: params %q and %q both renamed to %q", key, prev, from, to)
+			}
+			if _, hidden := o.Hidden[from]; hidden {
+				return fmt.Errorf("tool override %q: param %q is both hidden and renamed", key, from)
+			}
+			seen[to] = from
+		}
+	}
+	return nil
+}
+
+// exposes reports whether the tool passes the include/exclude filters.
+func (c *ToolsConfig) exposes(tool string) bool {
+	if c == nil {
+		return true
+	}
+	if matchAny(c.Exclude, tool) {
+		return false
+	}
+	return len(c.Include) == 0 || matchAny(c.Include, tool)
+}
+
+// override returns the override for tool (exact name before patterns), or nil.
+func (c *ToolsConfig) override(tool string) *ToolOverride {
+	if c == nil {
+		return nil
+	}
+	if o, ok := lookupToolRule(c.Overrides, tool); ok {
+		return &o
+	}
+	return nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupToolRule finds the rule for tool: an exact key wins, then the first matching pattern in sorted order.
+func lookupToolRule[T any](rules map[string]T, tool string) (T, bool) {
+	if rule, ok := rules[tool]; ok {
+		return rule, true
+	}
+	patterns := make([]string, 0, len(rules))
+	for pattern := range rules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return rules[pattern], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// filterTools drops the tools the server config doesn't expose.
+func filterTools(cfg *MCPServerConfig, tools []*mcp.Tool) []*mcp.Tool {
+	if cfg == nil || cfg.Tools == nil {
+		return tools
+	}
+	out := make([]*mcp.Tool, 0, len(tools))
+	hidden := make([]string, 0)
+	for _, t := range tools {
+		if cfg.Tools.exposes(t.Name) {
+			out = append(out, t)
+		} else {
+			hidden = append(hidden, t.Name)
+		}
+	}
+	if len(hidden) > 0 {
+		slog.Info("MCP tools filtered by config", "server", cfg.Name, "exposed", len(out), "hidden", hidden)
+	}
+	return out
+}
+
+// applySchema removes hidden params and renames params in a normalized parameters schema.
+func (o *ToolOverride) applySchema(schema map[string]any) {
+	props, _ := schema["properties"].(map[string]any)
+	required, _ := schema["required"].([]any)
+
+	for name := range o.Hidden {
+		delete(props, name)
+	}
+	for from, to := range o.Rename {
+		if p, ok := props[from]; ok {
+			delete(props, from)
+			props[to] = p
+		}
+	}
+
+	if required == nil {
+		return
+	}
+	kept := make([]any, 0, len(required))
+	for _, r := range required {
+		name, _ := r.(string)
+		if _, hidden := o.Hidden[name]; hidden {
+			continue
+		}
+		if to, ok := o.Rename[name]; ok {
+			name = to
+		}
+		kept = append(kept, name)
+	}
+	schema["required"] = kept
+}
+
+// applyArgs maps the model's arguments back to the server's param names and injects hidden values.
+// Hidden values always win over anything the model sent.
+func (o *ToolOverride) applyArgs(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args)+len(o.Hidden))
+	reverse := make(map[string]string, len(o.Rename))
+	for from, to := range o.Rename {
+		reverse[to] = from
+	}
+	for k, v := range args {
+		if from, ok := reverse[k]; ok {
+			k = from
+		}
+		out[k] = v
+	}
+	for k, v := range o.Hidden {
+		out[k] = v
+	}
+	return out
+}
+
+// toolOverride returns the override configured for a server's tool, or nil.
+func (m *Manager) toolOverride(server, tool string) *ToolOverride {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cfg, ok := m.configs[server]
+	if !ok {
+		return nil
+	}
+	return cfg.Tools.override(tool)
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxToolNameLen is OpenAI's limit for function names
+	maxToolNameLen = 64
+	// toolNameSep joins server and tool in the function names shown to the model
+	toolNameSep = "__"
+	// hashBytes and longHashBytes size the suffix of hashed names; the long one only resolves hash collisions
+	hashBytes     = 4
+	longHashBytes = 8
+)
+
+// invalidToolNameChars matches characters OpenAI does not accept in function names
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// hashSuffix matches the suffix of hashed names; clean names never end with it, so the two can't collide
+var hashSuffix = regexp.MustCompile(`_[0-9a-f]{8}$`)
+
+// toolRef identifies a tool on a registered server.
+type toolRef struct {
+	Server string
+	Tool   string
+}
+
+// toolNames is the reversible mapping between OpenAI function names and MCP tools.
+// Guarded by Manager.mu.
+type toolNames struct {
+	byName map[string]toolRef
+	byRef  map[toolRef]string
+}
+
+func newToolNames() *toolNames {
+	return &toolNames{
+		byName: make(map[string]toolRef),
+		byRef:  make(map[toolRef]string),
+	}
+}
+
+// assign returns the function name of server/tool, creating it on first use.
+// Names are "<server>__<tool>" when that is already valid and can't be mistaken for a hashed name;
+// otherwise the parts are sanitized, shortened to fit 64 characters and suffixed with a hash of the
+// original names. The name depends only on server and tool, not on what was assigned before.
+func (n *toolNames) assign(server, tool string) string {
+	ref := toolRef{Server: server, Tool: tool}
+	if name, ok := n.byRef[ref]; ok {
+		return name
+	}
+
+	name := server + toolNameSep + tool
+	lossy := strings.Contains(server, toolNameSep) || strings.Contains(tool, toolNameSep) ||
+		invalidToolNameChars.MatchString(name) || len(name) > maxToolNameLen || hashSuffix.MatchString(name)
+	if lossy {
+		name = hashedToolName(server, tool, hashBytes)
+	}
+	if other, taken := n.byName[name]; taken {
+		// two hashed names sharing a 32-bit suffix; don't shadow the other tool
+		slog.Warn("tool name hash collision", "name", name, "server", server, "tool", tool, "taken_by", other.Server+"/"+other.Tool)
+		name = hashedToolName(server, tool, longHashBytes)
+	}
+
+	n.byName[name] = ref
+	n.byRef[ref] = name
+	if lossy {
+		slog.Info("tool name mapped", "server", server, "tool", tool, "function", name)
+	}
+	return name
+}
+
+// resolve maps a model-issued function name back to its server and tool.
+func (n *toolNames) resolve(name string) (toolRef, bool) {
+	ref, ok := n.byName[name]
+	return ref, ok
+}
+
+// resetServer replaces the names of a server with those of tools, forgetting removed tools.
+func (n *toolNames) resetServer(server string, tools []string) {
+	n.dropServer(server)
+	for _, tool := range tools {
+		n.assign(server, tool)
+	}
+}
+
+// dropServer forgets every name of a server.
+func (n *toolNames) dropServer(server string) {
+	for ref, name := range n.byRef {
+		if ref.Server == server {
+			delete(n.byRef, ref)
+			delete(n.byName, name)
+		}
+	}
+}
+
+// hashedToolName builds a sanitized name that fits the limit, made unique by a hash of the originals.
+func hashedToolName(server, tool string, size int) string {
+	sum := sha256.Sum256([]byte(server + "\x00" + tool))
+	suffix := "_" + hex.EncodeToString(sum[:size])
+
+	s := sanitizeToolNamePart(server)
+	t := sanitizeToolNamePart(tool)
+	budget := maxToolNameLen - len(toolNameSep) - len(suffix)
+	if len(s)+len(t) > budget {
+		// keep at most half for the server, the tool name is more informative
+		maxServer := budget / 2
+		if len(s) > maxServer {
+			s = s[:maxServer]
+		}
+		if len(t) > budget-len(s) {
+			t = t[:budget-len(s)]
+		}
+	}
+	return s + toolNameSep + t + suffix
+}
+
+// sanitizeToolNamePart replaces unsupported characters and collapses "__" so the separator stays unique.
+func sanitizeToolNamePart(s string) string {
+	s = invalidToolNameChars.ReplaceAllString(s, "_")
+	for strings.Contains(s, toolNameSep) {
+		s = strings.ReplaceAll(s, toolNameSep, "_")
+	}
+	return s
+}
+
+// advertisedToolNames lists the MCP tool names a server's schemas are built from, synthetic resource tools included.
+func advertisedToolNames(session *mcp.ClientSession, tools []*mcp.Tool) []string {
+	names := make([]string, 0, len(tools)+2)
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	if supportsResources(session) {
+		names = append(names, listResourcesTool, readResourceTool)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToolName returns the function name the model uses for a server's tool.
+func (m *Manager) ToolName(server, tool string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.names.assign(server, tool)
+}
+
+// ResolveToolName maps a function name issued by the model back to its server and MCP tool name.
+func (m *Manager) ResolveToolName(name string) (server, tool string, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ref, ok := m.names.resolve(name)
+	return ref.Server, ref.Tool, ok
+}
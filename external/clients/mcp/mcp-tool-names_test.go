@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToolNamesAssign(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		tool   string
+		want   string // exact name, or "" when a hashed name is expected
+	}{
+		{"valid name kept", "github", "create_issue", "github__create_issue"},
+		{"dashes allowed", "my-srv", "get-page", "my-srv__get-page"},
+		{"invalid characters", "notion", "API.get page", ""},
+		{"separator in server", "a__b", "tool", ""},
+		{"separator in tool", "srv", "x__y", ""},
+		{"too long", "server", strings.Repeat("t", 70), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newToolNames()
+			got := n.assign(tt.server, tt.tool)
+			if tt.want != "" && got != tt.want {
+				t.Fatalf("assign(%q, %q) = %q, want %q", tt.server, tt.tool, got, tt.want)
+			}
+			if invalidToolNameChars.MatchString(got) || len(got) > maxToolNameLen {
+				t.Fatalf("assign(%q, %q) = %q is not a valid function name", tt.server, tt.tool, got)
+			}
+			if strings.Count(got, toolNameSep) != 1 {
+				t.Fatalf("assign(%q, %q) = %q must contain the separator exactly once", tt.server, tt.tool, got)
+			}
+			if again := n.assign(tt.server, tt.tool); again != got {
+				t.Fatalf("assign is not stable: %q then %q", got, again)
+			}
+			ref, ok := n.resolve(got)
+			if !ok || ref.Server != tt.server || ref.Tool != tt.tool {
+				t.Fatalf("resolve(%q) = %+v, %v; want %s/%s", got, ref, ok, tt.server, tt.tool)
+			}
+		})
+	}
+}
+
+func TestToolNamesAmbiguous(t *testing.T) {
+	n := newToolNames()
+	// both sanitize to the same characters; the hash keeps them apart
+	a := n.assign("srv", "get.page")
+	b := n.assign("srv", "get page")
+	if a == b {
+		t.Fatalf("distinct tools share the name %q", a)
+	}
+	// a valid name that looks like a hashed one is hashed as well
+	_, lookalike, _ := strings.Cut(a, toolNameSep)
+	c := n.assign("srv", lookalike)
+	if c == a {
+		t.Fatalf("tool %q took the name of get.page", lookalike)
+	}
+	if ref, _ := n.resolve(c); ref.Tool != lookalike {
+		t.Fatalf("resolve(%q) = %+v, want tool %q", c, ref, lookalike)
+	}
+	for _, name := range []string{a, b, c} {
+		if _, ok := n.resolve(name); !ok {
+			t.Fatalf("%q does not resolve", name)
+		}
+	}
+}
+
+func TestToolNamesOrderIndependent(t *testing.T) {
+	lookalike := strings.TrimPrefix(hashedToolName("srv", "get.page", hashBytes), "srv"+toolNameSep)
+	tools := []string{"get.page", lookalike, "get page", "search"}
+
+	forward, backward := newToolNames(), newToolNames()
+	want := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		want[tool] = forward.assign("srv", tool)
+	}
+	for i := len(tools) - 1; i >= 0; i-- {
+		if got := backward.assign("srv", tools[i]); got != want[tools[i]] {
+			t.Fatalf("name of %q depends on assignment order: %q vs %q", tools[i], got, want[tools[i]])
+		}
+	}
+	if want["search"] != "srv__search" {
+		t.Fatalf("clean name changed: %q", want["search"])
+	}
+}
+
+func TestToolNamesResetServer(t *testing.T) {
+	n := newToolNames()
+	other := n.assign("other", "tool")
+	removed := n.assign("srv", "old")
+	kept := n.assign("srv", "kept")
+
+	n.resetServer("srv", []string{"kept", "new"})
+	if _, ok := n.resolve(removed); ok {
+		t.Fatalf("%q of a removed tool still resolves", removed)
+	}
+	for _, name := range []string{kept, other, "srv__new"} {
+		if _, ok := n.resolve(name); !ok {
+			t.Fatalf("%q does not resolve after resetServer", name)
+		}
+	}
+}
+
+func TestToolNamesDropServer(t *testing.T) {
+	n := newToolNames()
+	keep := n.assign("keep", "tool")
+	drop := n.assign("drop", "tool")
+	n.dropServer("drop")
+	if _, ok := n.resolve(drop); ok {
+		t.Fatalf("%q still resolves after dropServer", drop)
+	}
+	if _, ok := n.resolve(keep); !ok {
+		t.Fatalf("%q of another server was dropped", keep)
+	}
+}
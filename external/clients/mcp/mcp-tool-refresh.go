@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const refreshTimeout = 15 * time.Second
+
+// refreshTools re-lists the server's tools after a notifications/tools/list_changed
+// and swaps in freshly built schemas, so the next completion request sees the current set.
+func (m *Manager) refreshTools(name string, session *mcp.ClientSession) {
+	if m.GetSession(name) != session {
+		// notification from a replaced or unregistered session
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	tools, err := listAllTools(ctx, session)
+	if err != nil {
+		slog.Error("failed to refresh tools", "server", name, "error", err)
+		return
+	}
+	cfg := m.serverConfig(name)
+	if cfg == nil {
+		return
+	}
+	tools = filterTools(cfg, tools)
+	schemas := m.buildToolSchemas(cfg, tools)
+	schemas = append(schemas, m.resourceToolSchemas(cfg, session, tools)...)
+
+	m.mu.Lock()
+	if m.sessions[name] != session {
+		m.mu.Unlock()
+		return
+	}
+	old := m.tools[name]
+	m.tools[name] = tools
+	m.schemas[name] = schemas
+	m.names.resetServer(name, advertisedToolNames(session, tools))
+	m.mu.Unlock()
+
+	added, removed, changed := diffTools(old, tools)
+	slog.Info("MCP tools refreshed",
+		"server", name,
+		"tool_count", len(tools),
+		"added", added,
+		"removed", removed,
+		"changed", changed,
+	)
+}
+
+// diffTools compares two tool lists by name; a tool is changed when its description or schemas differ.
+func diffTools(before, after []*mcp.Tool) (added, removed, changed []string) {
+	added, removed, changed = make([]string, 0), make([]string, 0), make([]string, 0)
+
+	prev := make(map[string]*mcp.Tool, len(before))
+	for _, t := range before {
+		prev[t.Name] = t
+	}
+	for _, t := range after {
+		old, ok := prev[t.Name]
+		if !ok {
+			added = append(added, t.Name)
+			continue
+		}
+		delete(prev, t.Name)
+		if toolFingerprint(old) != toolFingerprint(t) {
+			changed = append(changed, t.Name)
+		}
+	}
+	for n := range prev {
+		removed = append(removed, n)
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+// toolFingerprint serializes the parts of a tool the model depends on.
+func toolFingerprint(t *mcp.Tool) string {
+	b, err := json.Marshal(struct {
+		Description  string `json:"description"`
+		InputSchema  any    `json:"inputSchema"`
+		OutputSchema any    `json:"outputSchema"`
+	}{t.Description, t.InputSchema, t.OutputSchema})
+	if err != nil {
+		return t.Description
+	}
+	return string(b)
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// authRoundTripper injects static headers and credentials into every outgoing request.
+type authRoundTripper struct {
+	base      http.RoundTripper
+	headers   map[string]string
+	bearer    string
+	basicAuth *BasicAuthConfig
+}
+
+func (a *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range a.headers {
+		req.Header.Set(k, v)
+	}
+	if a.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+a.bearer)
+	}
+	if a.basicAuth != nil {
+		req.SetBasicAuth(a.basicAuth.Username, a.basicAuth.Password)
+	}
+	return a.base.RoundTrip(req)
+}
+
+// stderrLogger is an io.Writer that forwards a child process' stderr to slog line by line,
+// with the server's secrets redacted.
+type stderrLogger struct {
+	mu  sync.Mutex
+	cfg *MCPServerConfig
+	buf bytes.Buffer
+}
+
+func (s *stderrLogger) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Write(p)
+	for {
+		line, err := s.buf.ReadBytes('\n')
+		if err != nil {
+			// keep the partial line for the next write
+			s.buf.Write(line)
+			break
+		}
+		if text := bytes.TrimRight(line, "\r\n"); len(text) > 0 {
+			slog.Info("mcp server stderr", "server", s.cfg.Name, "line", s.cfg.redact(string(text)))
+		}
+	}
+	return len(p), nil
+}
+
+// buildTransport creates the MCP transport described by cfg.
+// For stdio servers the returned *exec.Cmd is the (not yet started) child process.
+func buildTransport(cfg *MCPServerConfig) (mcp.Transport, *exec.Cmd, error) {
+	httpClient := http.DefaultClient
+	if len(cfg.Headers) > 0 || cfg.APIKey != "" || cfg.BasicAuth != nil {
+		httpClient = &http.Client{
+			Transport: &authRoundTripper{
+				base:      http.DefaultTransport,
+				headers:   cfg.Headers,
+				bearer:    cfg.APIKey,
+				basicAuth: cfg.BasicAuth,
+			},
+		}
+	}
+
+	switch cfg.Transport {
+	case "", TransportStreamable:
+		return &mcp.StreamableClientTransport{Endpoint: cfg.Endpoint, HTTPClient: httpClient}, nil, nil
+	case TransportSSE:
+		return &mcp.SSEClientTransport{Endpoint: cfg.Endpoint, HTTPClient: httpClient}, nil, nil
+	case TransportStdio:
+		// not bound to ctx: the process must outlive the registration call
+		cmd := exec.Command(cfg.Command, cfg.Args...)
+		cmd.Dir = cfg.WorkDir
+		cmd.Env = os.Environ()
+		for k, v := range cfg.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		cmd.Stderr = &stderrLogger{cfg: cfg}
+		return &mcp.CommandTransport{Command: cmd}, cmd, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported transport %q for %s", cfg.Transport, cfg.Name)
+	}
+}
+
+// closeSession shuts a session down. For stdio servers the transport closes stdin, waits for the
+// process to exit and signals it if it doesn't; proc is only killed when that fails.
+func closeSession(server string, session *mcp.ClientSession, proc *os.Process) {
+	if session == nil {
+		killProcess(server, proc)
+		return
+	}
+	// Close also reports a non-zero exit status; killing an exited process is a no-op
+	if err := session.Close(); err != nil {
+		killProcess(server, proc)
+	}
+}
+
+// killProcess kills a stdio server's child process if it is still running.
+// It never reads the process state, which the transport's Wait may be writing concurrently.
+func killProcess(server string, proc *os.Process) {
+	if proc == nil {
+		return
+	}
+	if err := proc.Kill(); err != nil {
+		if !errors.Is(err, os.ErrProcessDone) {
+			slog.Warn("failed to kill MCP server process", "server", server, "pid", proc.Pid, "error", err)
+		}
+		return
+	}
+	slog.Info("killed MCP server process", "server", server, "pid", proc.Pid)
+}
@@ -0,0 +1,319 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxArgIssues caps how many problems are reported back to the model
+	maxArgIssues = 20
+	// maxRefDepth guards against $ref cycles while validating
+	maxRefDepth = 32
+)
+
+// ArgIssue is one problem found while validating tool arguments against the tool's input schema.
+type ArgIssue struct {
+	Path    string `json:"path"` // dotted path of the offending value ("" = the arguments object)
+	Message string `json:"message"`
+}
+
+// ValidateToolArgs checks model-issued arguments against the cached InputSchema of the tool.
+// Arguments are validated the way they will be sent, after renames and hidden values are applied.
+// Unknown tools and synthetic tools without a server schema return no issues.
+func (m *Manager) ValidateToolArgs(toolName string, args map[string]any) []ArgIssue {
+	server, name, ok := m.ResolveToolName(toolName)
+	if !ok {
+		return nil
+	}
+	tool := m.findTool(server, name)
+	if tool == nil || tool.InputSchema == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return nil
+	}
+
+	if cfg := m.serverConfig(server); cfg != nil && cfg.StrictSchemas {
+		args, _ = dropNulls(args).(map[string]any)
+	}
+
+	override := m.toolOverride(server, name)
+	if override != nil {
+		args = override.applyArgs(args)
+	}
+
+	// round-trip through JSON so injected config values use JSON types (float64, []any, ...)
+	var instance any
+	if b, err := json.Marshal(args); err == nil {
+		_ = json.Unmarshal(b, &instance)
+	}
+
+	v := &argValidator{root: schema}
+	v.validate(instance, schema, "", 0)
+
+	// report renamed params under the name the model knows
+	if override != nil {
+		for i, issue := range v.issues {
+			head, rest, _ := strings.Cut(issue.Path, ".")
+			if to, ok := override.Rename[head]; ok {
+				v.issues[i].Path = strings.TrimSuffix(to+"."+rest, ".")
+			}
+		}
+	}
+	return v.issues
+}
+
+// argValidator implements the subset of JSON Schema that tool input schemas use in practice.
+type argValidator struct {
+	root   map[string]any
+	issues []ArgIssue
+}
+
+func (v *argValidator) add(path, format string, a ...any) {
+	if len(v.issues) < maxArgIssues {
+		v.issues = append(v.issues, ArgIssue{Path: path, Message: fmt.Sprintf(format, a...)})
+	}
+}
+
+// matches reports whether value satisfies schema without recording issues.
+func (v *argValidator) matches(value any, schema map[string]any, depth int) bool {
+	probe := &argValidator{root: v.root}
+	probe.validate(value, schema, "", depth)
+	return len(probe.issues) == 0
+}
+
+func (v *argValidator) validate(value any, schema map[string]any, path string, depth int) {
+	if schema == nil || depth > maxRefDepth {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := resolveLocalRef(v.root, ref)
+		if !ok {
+			return // unresolvable references are not the model's fault
+		}
+		v.validate(value, target, path, depth+1)
+	}
+
+	if value == nil && schema["nullable"] == true {
+		return
+	}
+
+	if branches, ok := schema["allOf"].([]any); ok {
+		for _, b := range branches {
+			if bs, ok := b.(map[string]any); ok {
+				v.validate(value, bs, path, depth+1)
+			}
+		}
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		branches, ok := schema[key].([]any)
+		if !ok || len(branches) == 0 {
+			continue
+		}
+		matched := false
+		for _, b := range branches {
+			if bs, ok := b.(map[string]any); ok && v.matches(value, bs, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.add(path, "does not match any of the allowed schemas (%s)", key)
+		}
+	}
+
+	if !v.checkType(value, schema["type"], path) {
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			b, _ := json.Marshal(enum)
+			v.add(path, "must be one of %s", b)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		b, _ := json.Marshal(c)
+		v.add(path, "must be %s", b)
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(val, schema, path, depth)
+	case []any:
+		v.validateArray(val, schema, path, depth)
+	case string:
+		n := len([]rune(val))
+		if min, ok := number(schema["minLength"]); ok && float64(n) < min {
+			v.add(path, "must be at least %v characters", min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(n) > max {
+			v.add(path, "must be at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(val) {
+				v.add(path, "must match pattern %q", pattern)
+			}
+		}
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && val < min {
+			v.add(path, "must be >= %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && val > max {
+			v.add(path, "must be <= %v", max)
+		}
+		if min, ok := number(schema["exclusiveMinimum"]); ok && val <= min {
+			v.add(path, "must be > %v", min)
+		}
+		if max, ok := number(schema["exclusiveMaximum"]); ok && val >= max {
+			v.add(path, "must be < %v", max)
+		}
+	}
+}
+
+func (v *argValidator) validateObject(obj map[string]any, schema map[string]any, path string, depth int) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := obj[name]; name != "" && !present {
+				v.add(joinPath(path, name), "required property is missing")
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if ps, ok := props[k].(map[string]any); ok {
+			v.validate(obj[k], ps, joinPath(path, k), depth+1)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.add(joinPath(path, k), "unknown property")
+			}
+		case map[string]any:
+			v.validate(obj[k], extra, joinPath(path, k), depth+1)
+		}
+	}
+}
+
+func (v *argValidator) validateArray(arr []any, schema map[string]any, path string, depth int) {
+	if min, ok := number(schema["minItems"]); ok && float64(len(arr)) < min {
+		v.add(path, "must have at least %v items", min)
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(arr)) > max {
+		v.add(path, "must have at most %v items", max)
+	}
+
+	// tuple forms: prefixItems (2020-12) or an items array (draft-07)
+	prefix, _ := schema["prefixItems"].([]any)
+	if tuple, ok := schema["items"].([]any); ok {
+		prefix = tuple
+	}
+	for i, item := range arr {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefix) {
+			if ps, ok := prefix[i].(map[string]any); ok {
+				v.validate(item, ps, itemPath, depth+1)
+			}
+			continue
+		}
+		if is, ok := schema["items"].(map[string]any); ok {
+			v.validate(item, is, itemPath, depth+1)
+		}
+	}
+}
+
+// checkType reports whether value has one of the schema's types, recording an issue otherwise.
+func (v *argValidator) checkType(value any, t any, path string) bool {
+	var types []string
+	switch tt := t.(type) {
+	case string:
+		types = []string{tt}
+	case []any:
+		for _, x := range tt {
+			if s, ok := x.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	if len(types) == 0 {
+		return true
+	}
+	for _, want := range types {
+		if hasJSONType(value, want) {
+			return true
+		}
+	}
+	v.add(path, "must be of type %s, got %s", strings.Join(types, " or "), jsonTypeOf(value))
+	return false
+}
+
+func hasJSONType(value any, want string) bool {
+	switch want {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonTypeOf(value) == want
+	}
+}
+
+func jsonTypeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newValidationManager returns a Manager holding a single tool "search" of server "srv" with the given input schema.
+func newValidationManager(t *testing.T, cfg *MCPServerConfig, schema string) *Manager {
+	t.Helper()
+	var tool mcp.Tool
+	if err := json.Unmarshal([]byte(`{"name":"search","inputSchema":`+schema+`}`), &tool); err != nil {
+		t.Fatalf("bad test schema: %v", err)
+	}
+	m := &Manager{
+		tools:   map[string][]*mcp.Tool{"srv": {&tool}},
+		configs: map[string]*MCPServerConfig{"srv": cfg},
+		names:   newToolNames(),
+	}
+	m.ToolName("srv", "search")
+	return m
+}
+
+func TestValidateToolArgs(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"q":      {"type": "string", "minLength": 2},
+			"limit":  {"type": "integer", "minimum": 1, "maximum": 50},
+			"sort":   {"enum": ["asc", "desc"]},
+			"cursor": {"type": "string", "nullable": true},
+			"after":  {"type": ["string", "null"]},
+			"filter": {"anyOf": [{"type": "string"}, {"$ref": "#/$defs/Range"}]},
+			"mode":   {"oneOf": [{"const": "fast"}, {"const": "exact"}]},
+			"tags":   {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+		},
+		"required": ["q"],
+		"additionalProperties": false,
+		"$defs": {
+			"Range": {
+				"type": "object",
+				"properties": {"from": {"type": "number"}, "to": {"type": "number"}},
+				"required": ["from"]
+			}
+		}
+	}`
+
+	tests := []struct {
+		name string
+		args string
+		want []ArgIssue // path and message prefix
+	}{
+		{"valid", `{"q":"go","limit":10,"sort":"asc","tags":["a"]}`, nil},
+		{"missing required", `{"limit":10}`, []ArgIssue{{"q", "required property is missing"}}},
+		{"wrong type", `{"q":"go","limit":"10"}`, []ArgIssue{{"limit", "must be of type integer, got string"}}},
+		{"integer with fraction", `{"q":"go","limit":1.5}`, []ArgIssue{{"limit", "must be of type integer"}}},
+		{"out of range", `{"q":"go","limit":99}`, []ArgIssue{{"limit", "must be <= 50"}}},
+		{"too short", `{"q":"g"}`, []ArgIssue{{"q", "must be at least 2 characters"}}},
+		{"enum", `{"q":"go","sort":"up"}`, []ArgIssue{{"sort", `must be one of ["asc","desc"]`}}},
+		{"unknown property", `{"q":"go","page":2}`, []ArgIssue{{"page", "unknown property"}}},
+		{"nullable keyword", `{"q":"go","cursor":null}`, nil},
+		{"null in type list", `{"q":"go","after":null}`, nil},
+		{"null not allowed", `{"q":null}`, []ArgIssue{{"q", "must be of type string, got null"}}},
+		{"anyOf first branch", `{"q":"go","filter":"recent"}`, nil},
+		{"anyOf $ref branch", `{"q":"go","filter":{"from":1,"to":2}}`, nil},
+		{"anyOf no branch", `{"q":"go","filter":{"to":2}}`, []ArgIssue{{"filter", "does not match any of the allowed schemas (anyOf)"}}},
+		{"oneOf no branch", `{"q":"go","mode":"slow"}`, []ArgIssue{{"mode", "does not match any of the allowed schemas (oneOf)"}}},
+		{"array items", `{"q":"go","tags":["a",1]}`, []ArgIssue{{"tags[1]", "must be of type string, got number"}}},
+		{"array length", `{"q":"go","tags":["a","b","c"]}`, []ArgIssue{{"tags", "must have at most 2 items"}}},
+	}
+
+	m := newValidationManager(t, &MCPServerConfig{Name: "srv"}, schema)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+				t.Fatalf("bad test args: %v", err)
+			}
+			checkIssues(t, m.ValidateToolArgs("srv__search", args), tt.want)
+		})
+	}
+}
+
+func TestValidateToolArgsOverrides(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"q":     {"type": "string"},
+			"token": {"type": "string"},
+			"page":  {"type": "integer"}
+		},
+		"required": ["q", "token"]
+	}`
+	cfg := &MCPServerConfig{
+		Name: "srv",
+		Tools: &ToolsConfig{Overrides: map[string]ToolOverride{
+			"search": {Rename: map[string]string{"q": "query"}, Hidden: map[string]any{"token": "secret"}},
+		}},
+	}
+	m := newValidationManager(t, cfg, schema)
+
+	tests := []struct {
+		name string
+		args string
+		want []ArgIssue
+	}{
+		{"renamed and hidden params", `{"query":"go"}`, nil},
+		{"issue reported under the new name", `{"query":5}`, []ArgIssue{{"query", "must be of type string, got number"}}},
+		{"missing renamed param", `{"page":1}`, []ArgIssue{{"query", "required property is missing"}}},
+		{"hidden value wins", `{"query":"go","token":7}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+				t.Fatalf("bad test args: %v", err)
+			}
+			checkIssues(t, m.ValidateToolArgs("srv__search", args), tt.want)
+		})
+	}
+}
+
+func TestValidateToolArgsStrictDropsNulls(t *testing.T) {
+	const schema = `{"type":"object","properties":{"q":{"type":"string"},"page":{"type":"integer"}},"required":["q"]}`
+	args := map[string]any{"q": "go", "page": nil}
+
+	lenient := newValidationManager(t, &MCPServerConfig{Name: "srv"}, schema)
+	checkIssues(t, lenient.ValidateToolArgs("srv__search", args), []ArgIssue{{"page", "must be of type integer, got null"}})
+
+	strict := newValidationManager(t, &MCPServerConfig{Name: "srv", StrictSchemas: true}, schema)
+	checkIssues(t, strict.ValidateToolArgs("srv__search", args), nil)
+}
+
+func TestValidateToolArgsUnknownTool(t *testing.T) {
+	m := newValidationManager(t, &MCPServerConfig{Name: "srv"}, `{"type":"object"}`)
+	if issues := m.ValidateToolArgs("srv__missing", map[string]any{"x": 1}); issues != nil {
+		t.Fatalf("unknown tool: got issues %v, want none", issues)
+	}
+}
+
+// checkIssues compares issue paths and message prefixes.
+func checkIssues(t *testing.T, got, want []ArgIssue) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got issues %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Path != want[i].Path || !strings.HasPrefix(got[i].Message, want[i].Message) {
+			t.Errorf("issue %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package openai
 
 import (
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
+	"github.com/pavitra93/11-openai-chats/external/clients/llm"
 )
 
 type openAIServiceClient struct {
@@ -12,23 +15,81 @@ type openAIServiceClient struct {
 }
 
 type OpenAIConfig struct {
-	OpenAPIClient *openai.Client
-	MaxTokens     int64
-	Temperature   float64
+	Provider      llm.Provider     // chat completion backend (OpenAI, Anthropic, ...)
+	Model         string           // guarded by mu once the chat runs; use CurrentModel / SetModel
+	Generation    GenerationParams // guarded by mu once the chat runs; use GenerationSettings / SetGeneration
 	SystemMessage string
 	History       *openai.ChatCompletionNewParams
 	AllowHistory  bool
 	HistorySize   int
+
+	// ParallelToolCalls sets parallel_tool_calls on requests (nil = API default)
+	ParallelToolCalls *bool
+	// MaxParallelToolCalls bounds how many tool calls of one assistant turn run at once (0 = default)
+	MaxParallelToolCalls int
+	// Budget bounds the tool-calling loop of a single user turn
+	Budget LoopBudget
+	// ShowReasoning displays the reasoning summaries some OpenAI-compatible servers add to messages
+	// (reasoning_content / reasoning). Chat Completions does not define these fields.
+	ShowReasoning bool
+
+	mu sync.RWMutex
+}
+
+// NewChatParams returns request params with the current model and generation settings.
+func (c *OpenAIConfig) NewChatParams() openai.ChatCompletionNewParams {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p := openai.ChatCompletionNewParams{Model: c.Model}
+	c.Generation.Apply(&p)
+	return p
+}
+
+// CurrentModel returns the chat model.
+func (c *OpenAIConfig) CurrentModel() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Model
+}
+
+// SetModel switches the chat model for the following requests.
+func (c *OpenAIConfig) SetModel(model string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Model = model
+}
+
+// GenerationSettings returns a copy of the generation settings.
+func (c *OpenAIConfig) GenerationSettings() GenerationParams {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Generation
+}
+
+// SetGeneration changes one generation setting for the following requests (see GenerationParams.Set).
+func (c *OpenAIConfig) SetGeneration(key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Generation.Set(key, value)
+}
+
+// LoopBudget limits how much work one user turn may do before the model must answer.
+// Zero values fall back to the defaults of the send/receive strategies.
+type LoopBudget struct {
+	MaxIterations    int           // completion requests per turn
+	MaxToolCalls     int           // tool calls executed per turn
+	MaxDuration      time.Duration // wall-clock time per turn
+	MaxTokens        int64         // total tokens per turn (0 = unlimited)
+	MaxRepeatedCalls int           // identical tool calls (same name and arguments) allowed per turn
 }
 
 var openAIInstance *openAIServiceClient
 var once sync.Once
 
-func GetOpenAIClientInstance(openapiKey string) *openAIServiceClient {
+// GetOpenAIClientInstance returns the shared client, created with opts on first use.
+func GetOpenAIClientInstance(opts ...option.RequestOption) *openAIServiceClient {
 	once.Do(func() {
-		client := openai.NewClient(
-			option.WithAPIKey(openapiKey), // defaults to os.LookupEnv("OPENAI_API_KEY")
-		)
+		client := openai.NewClient(opts...)
 		openAIInstance = &openAIServiceClient{
 			OpenAIClient: &client,
 		}
@@ -37,3 +98,57 @@ func GetOpenAIClientInstance(openapiKey string) *openAIServiceClient {
 	return openAIInstance
 
 }
+
+// ClientOptions returns the request options of an OpenAI-compatible provider profile.
+// The API key is always set, so a keyless local server never receives OPENAI_API_KEY from the environment.
+func ClientOptions(profile *llm.ProviderProfile) []option.RequestOption {
+	opts := []option.RequestOption{option.WithAPIKey(profile.APIKey)}
+	if profile.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(profile.BaseURL))
+	}
+	if profile.Organization != "" {
+		opts = append(opts, option.WithOrganization(profile.Organization))
+	}
+	if profile.Project != "" {
+		opts = append(opts, option.WithProject(profile.Project))
+	}
+	for k, v := range profile.Headers {
+		opts = append(opts, option.WithHeader(k, v))
+	}
+	return opts
+}
+
+// visionModelPrefixes lists model families that accept image inputs
+var visionModelPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-4-turbo", "gpt-5", "o1", "o3", "o4", "claude-"}
+
+// SupportsVision reports whether the model accepts image content parts.
+func SupportsVision(model string) bool {
+	for _, prefix := range visionModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// reasoningModelPrefixes lists model families that reason before answering; they take
+// max_completion_tokens and reasoning_effort and reject the classic sampling parameters
+var reasoningModelPrefixes = []string{"o1", "o3", "o4", "gpt-5"}
+
+// IsReasoningModel reports whether the model is a reasoning model. GPT-5 chat variants are not.
+func IsReasoningModel(model string) bool {
+	if strings.Contains(model, "-chat") {
+		return false
+	}
+	for _, prefix := range reasoningModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsVerbosity reports whether the model accepts the verbosity parameter (GPT-5 models only).
+func SupportsVerbosity(model string) bool {
+	return strings.HasPrefix(model, "gpt-5")
+}
@@ -0,0 +1,205 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// maxStopSequences is the API limit for stop sequences
+const maxStopSequences = 4
+
+// GenerationKeys lists the settings accepted by GenerationParams.Set, in display order.
+var GenerationKeys = []string{"temperature", "top_p", "max_tokens", "stop", "seed", "presence_penalty", "frequency_penalty", "verbosity", "reasoning_effort"}
+
+// GenerationParams are the sampling settings sent with every chat request.
+// Unset values (nil, 0 or empty) are not sent, so the API default applies.
+type GenerationParams struct {
+	Temperature      *float64
+	TopP             *float64
+	MaxTokens        int64
+	Stop             []string
+	Seed             *int64
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	Verbosity        string // "low", "medium" or "high" (GPT-5 models)
+	ReasoningEffort  string // "minimal", "low", "medium" or "high" (reasoning models)
+}
+
+// Set parses and validates one setting by key. "default" (or an empty value) unsets it.
+func (g *GenerationParams) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+	unset := value == "" || value == "default"
+
+	switch key {
+	case "temperature":
+		return setFloat(&g.Temperature, "temperature", value, unset, 0, 2)
+	case "top_p":
+		return setFloat(&g.TopP, "top_p", value, unset, 0, 1)
+	case "presence_penalty":
+		return setFloat(&g.PresencePenalty, "presence_penalty", value, unset, -2, 2)
+	case "frequency_penalty":
+		return setFloat(&g.FrequencyPenalty, "frequency_penalty", value, unset, -2, 2)
+	case "max_tokens":
+		if unset {
+			g.MaxTokens = 0
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("max_tokens must be a positive integer, got %q", value)
+		}
+		g.MaxTokens = n
+	case "seed":
+		if unset {
+			g.Seed = nil
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("seed must be an integer, got %q", value)
+		}
+		g.Seed = &n
+	case "stop":
+		if unset {
+			g.Stop = nil
+			return nil
+		}
+		stop, err := parseStopSequences(value)
+		if err != nil {
+			return err
+		}
+		g.Stop = stop
+	case "verbosity":
+		if unset {
+			g.Verbosity = ""
+			return nil
+		}
+		if value != "low" && value != "medium" && value != "high" {
+			return fmt.Errorf("verbosity must be low, medium or high, got %q", value)
+		}
+		g.Verbosity = value
+	case "reasoning_effort":
+		if unset {
+			g.ReasoningEffort = ""
+			return nil
+		}
+		if value != "minimal" && value != "low" && value != "medium" && value != "high" {
+			return fmt.Errorf("reasoning_effort must be minimal, low, medium or high, got %q", value)
+		}
+		g.ReasoningEffort = value
+	default:
+		return fmt.Errorf("unknown setting %q (want one of %s)", key, strings.Join(GenerationKeys, ", "))
+	}
+	return nil
+}
+
+// Get formats one setting for display; unset values read "default".
+func (g GenerationParams) Get(key string) string {
+	formatFloat := func(f *float64) string {
+		if f == nil {
+			return "default"
+		}
+		return strconv.FormatFloat(*f, 'g', -1, 64)
+	}
+	switch key {
+	case "temperature":
+		return formatFloat(g.Temperature)
+	case "top_p":
+		return formatFloat(g.TopP)
+	case "presence_penalty":
+		return formatFloat(g.PresencePenalty)
+	case "frequency_penalty":
+		return formatFloat(g.FrequencyPenalty)
+	case "max_tokens":
+		if g.MaxTokens > 0 {
+			return strconv.FormatInt(g.MaxTokens, 10)
+		}
+	case "seed":
+		if g.Seed != nil {
+			return strconv.FormatInt(*g.Seed, 10)
+		}
+	case "stop":
+		if len(g.Stop) > 0 {
+			b, _ := json.Marshal(g.Stop)
+			return string(b)
+		}
+	case "verbosity":
+		if g.Verbosity != "" {
+			return g.Verbosity
+		}
+	case "reasoning_effort":
+		if g.ReasoningEffort != "" {
+			return g.ReasoningEffort
+		}
+	}
+	return "default"
+}
+
+// Apply sets the configured values on a request.
+func (g GenerationParams) Apply(p *openai.ChatCompletionNewParams) {
+	if g.Temperature != nil {
+		p.Temperature = openai.Float(*g.Temperature)
+	}
+	if g.TopP != nil {
+		p.TopP = openai.Float(*g.TopP)
+	}
+	if g.MaxTokens > 0 {
+		p.MaxTokens = openai.Int(g.MaxTokens)
+	}
+	if len(g.Stop) > 0 {
+		p.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: g.Stop}
+	}
+	if g.Seed != nil {
+		p.Seed = openai.Int(*g.Seed)
+	}
+	if g.PresencePenalty != nil {
+		p.PresencePenalty = openai.Float(*g.PresencePenalty)
+	}
+	if g.FrequencyPenalty != nil {
+		p.FrequencyPenalty = openai.Float(*g.FrequencyPenalty)
+	}
+	if g.Verbosity != "" {
+		p.Verbosity = openai.ChatCompletionNewParamsVerbosity(g.Verbosity)
+	}
+	if g.ReasoningEffort != "" {
+		p.ReasoningEffort = shared.ReasoningEffort(g.ReasoningEffort)
+	}
+}
+
+func setFloat(dst **float64, key, value string, unset bool, min, max float64) error {
+	if unset {
+		*dst = nil
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < min || f > max {
+		return fmt.Errorf("%s must be a number between %g and %g, got %q", key, min, max, value)
+	}
+	*dst = &f
+	return nil
+}
+
+// parseStopSequences accepts a JSON array (for sequences with commas or escapes) or a comma-separated list.
+func parseStopSequences(value string) ([]string, error) {
+	var stop []string
+	if strings.HasPrefix(value, "[") {
+		if err := json.Unmarshal([]byte(value), &stop); err != nil {
+			return nil, fmt.Errorf("stop must be a JSON array of strings: %w", err)
+		}
+	} else {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				stop = append(stop, s)
+			}
+		}
+	}
+	if len(stop) > maxStopSequences {
+		return nil, fmt.Errorf("at most %d stop sequences are allowed, got %d", maxStopSequences, len(stop))
+	}
+	return stop, nil
+}
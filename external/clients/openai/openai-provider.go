@@ -0,0 +1,103 @@
+package openai
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+	"github.com/pavitra93/11-openai-chats/external/clients/llm"
+)
+
+// Provider is the llm.Provider of the OpenAI chat completions API and servers compatible with it.
+type Provider struct {
+	Client      *openai.Client
+	NativeTools bool // false for servers without tool calling
+}
+
+// NewProvider wraps an OpenAI client.
+func NewProvider(client *openai.Client) *Provider {
+	return &Provider{Client: client, NativeTools: true}
+}
+
+// NewProviderFromProfile creates the shared client from an OpenAI-compatible profile and wraps it.
+func NewProviderFromProfile(profile *llm.ProviderProfile) *Provider {
+	client := GetOpenAIClientInstance(ClientOptions(profile)...).OpenAIClient
+	return &Provider{Client: client, NativeTools: profile.SupportsNativeTools()}
+}
+
+func (p *Provider) Name() string { return "openai" }
+
+func (p *Provider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{NativeTools: p.NativeTools}
+}
+
+func (p *Provider) Complete(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	return p.Client.Chat.Completions.New(ctx, adaptParams(params))
+}
+
+func (p *Provider) Stream(ctx context.Context, params openai.ChatCompletionNewParams) llm.ChunkStream {
+	return p.Client.Chat.Completions.NewStreaming(ctx, adaptParams(params))
+}
+
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	out := make([]string, 0)
+	iter := p.Client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		out = append(out, iter.Current().ID)
+	}
+	return out, iter.Err()
+}
+
+// droppedParamsLogged remembers the models for which dropped parameters were already logged
+var droppedParamsLogged sync.Map
+
+// adaptParams fits a request to the model's capabilities. Reasoning models take max_completion_tokens
+// and reject temperature, top_p, penalties and stop; other models reject reasoning_effort.
+// Only GPT-5 models accept verbosity.
+func adaptParams(params openai.ChatCompletionNewParams) openai.ChatCompletionNewParams {
+	dropped := make([]string, 0)
+	if IsReasoningModel(params.Model) {
+		if params.MaxTokens.Valid() {
+			if !params.MaxCompletionTokens.Valid() {
+				params.MaxCompletionTokens = params.MaxTokens
+			}
+			params.MaxTokens = param.Opt[int64]{}
+		}
+		if params.Temperature.Valid() {
+			params.Temperature = param.Opt[float64]{}
+			dropped = append(dropped, "temperature")
+		}
+		if params.TopP.Valid() {
+			params.TopP = param.Opt[float64]{}
+			dropped = append(dropped, "top_p")
+		}
+		if params.PresencePenalty.Valid() {
+			params.PresencePenalty = param.Opt[float64]{}
+			dropped = append(dropped, "presence_penalty")
+		}
+		if params.FrequencyPenalty.Valid() {
+			params.FrequencyPenalty = param.Opt[float64]{}
+			dropped = append(dropped, "frequency_penalty")
+		}
+		if len(params.Stop.OfStringArray) > 0 || params.Stop.OfString.Valid() {
+			params.Stop = openai.ChatCompletionNewParamsStopUnion{}
+			dropped = append(dropped, "stop")
+		}
+	} else if params.ReasoningEffort != "" {
+		params.ReasoningEffort = ""
+		dropped = append(dropped, "reasoning_effort")
+	}
+	if params.Verbosity != "" && !SupportsVerbosity(params.Model) {
+		params.Verbosity = ""
+		dropped = append(dropped, "verbosity")
+	}
+
+	if len(dropped) > 0 {
+		if _, logged := droppedParamsLogged.LoadOrStore(params.Model, true); !logged {
+			slog.Info("parameters not supported by the model are not sent", "model", params.Model, "reasoning_model", IsReasoningModel(params.Model), "dropped", dropped)
+		}
+	}
+	return params
+}
@@ -0,0 +1,169 @@
+package send_receive
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	client_openai "github.com/pavitra93/11-openai-chats/external/clients/openai"
+)
+
+// Defaults for the per-turn loop budget
+const (
+	defaultMaxIterations    = 10
+	defaultMaxToolCalls     = 25
+	defaultMaxTurnDuration  = 5 * time.Minute
+	defaultMaxRepeatedCalls = 2
+)
+
+// finalAnswerInstruction is sent once a budget is exhausted so the model wraps up instead of calling more tools.
+const finalAnswerInstruction = "The tool-use budget for this turn is exhausted (%s). Do not call any more tools. " +
+	"Answer the user now using the information gathered so far, and say briefly what is still missing."
+
+// loopController enforces the LoopBudget of one user turn.
+type loopController struct {
+	budget     client_openai.LoopBudget
+	start      time.Time
+	iterations int
+	toolCalls  int
+	tokens     int64
+	prompt     int64          // prompt tokens over all requests of the turn
+	cached     int64          // prompt tokens served from OpenAI's prompt cache
+	reasoning  int64          // completion tokens spent on hidden reasoning
+	seen       map[string]int // tool call fingerprint -> times requested
+	reason     string         // why the budget was exhausted ("" while within budget)
+}
+
+func newLoopController(budget client_openai.LoopBudget) *loopController {
+	if budget.MaxIterations <= 0 {
+		budget.MaxIterations = defaultMaxIterations
+	}
+	if budget.MaxToolCalls <= 0 {
+		budget.MaxToolCalls = defaultMaxToolCalls
+	}
+	if budget.MaxDuration <= 0 {
+		budget.MaxDuration = defaultMaxTurnDuration
+	}
+	if budget.MaxRepeatedCalls <= 0 {
+		budget.MaxRepeatedCalls = defaultMaxRepeatedCalls
+	}
+	return &loopController{
+		budget: budget,
+		start:  time.Now(),
+		seen:   make(map[string]int),
+	}
+}
+
+// recordCompletion counts a completion request and its token usage.
+func (c *loopController) recordCompletion(reqID string, usage openai.CompletionUsage) {
+	c.iterations++
+	c.tokens += usage.TotalTokens
+	c.prompt += usage.PromptTokens
+	c.cached += usage.PromptTokensDetails.CachedTokens
+	c.reasoning += usage.CompletionTokensDetails.ReasoningTokens
+	slog.Info("completion usage", "req", reqID, "iteration", c.iterations,
+		"prompt_tokens", usage.PromptTokens, "cached_tokens", usage.PromptTokensDetails.CachedTokens,
+		"completion_tokens", usage.CompletionTokens, "reasoning_tokens", usage.CompletionTokensDetails.ReasoningTokens,
+		"total_tokens", usage.TotalTokens)
+}
+
+// logUsage reports the token usage of the whole turn, including how much of the prompt hit the cache.
+func (c *loopController) logUsage(reqID string) {
+	hitRate := 0.0
+	if c.prompt > 0 {
+		hitRate = float64(c.cached) / float64(c.prompt)
+	}
+	slog.Info("turn usage", "req", reqID, "requests", c.iterations, "tool_calls", c.toolCalls,
+		"prompt_tokens", c.prompt, "cached_tokens", c.cached, "cache_hit_rate", fmt.Sprintf("%.0f%%", hitRate*100),
+		"reasoning_tokens", c.reasoning, "total_tokens", c.tokens, "elapsed", time.Since(c.start))
+}
+
+// deadline is when the turn's wall-clock budget runs out.
+func (c *loopController) deadline() time.Time {
+	return c.start.Add(c.budget.MaxDuration)
+}
+
+// exhausted reports why the turn must stop calling tools, or "" while it is within budget.
+func (c *loopController) exhausted() string {
+	if c.reason != "" {
+		return c.reason
+	}
+	switch {
+	case c.iterations >= c.budget.MaxIterations:
+		c.reason = fmt.Sprintf("reached %d model requests", c.budget.MaxIterations)
+	case c.toolCalls >= c.budget.MaxToolCalls:
+		c.reason = fmt.Sprintf("reached %d tool calls", c.budget.MaxToolCalls)
+	case !time.Now().Before(c.deadline()):
+		c.reason = fmt.Sprintf("turn ran longer than %s", c.budget.MaxDuration)
+	case c.budget.MaxTokens > 0 && c.tokens >= c.budget.MaxTokens:
+		c.reason = fmt.Sprintf("used %d of %d tokens", c.tokens, c.budget.MaxTokens)
+	}
+	if c.reason != "" {
+		slog.Warn("turn budget exhausted", "reason", c.reason, "iterations", c.iterations, "tool_calls", c.toolCalls, "tokens", c.tokens, "elapsed", time.Since(c.start))
+	}
+	return c.reason
+}
+
+// admit decides which tool calls of an assistant message may run. Rejected calls get a
+// tool_error answer in rejections (keyed by index) so every tool_call is still answered.
+func (c *loopController) admit(toolCalls []openai.ChatCompletionMessageToolCallUnion) (admitted []int, rejections map[int]string) {
+	rejections = make(map[int]string)
+	repeats := 0
+	for i, toolCall := range toolCalls {
+		if toolCall.Type != "function" {
+			continue
+		}
+
+		key := toolCallFingerprint(toolCall)
+		c.seen[key]++
+		if c.seen[key] > c.budget.MaxRepeatedCalls {
+			repeats++
+			slog.Warn("repeated tool call rejected", "tool", toolCall.Function.Name, "times", c.seen[key])
+			rejections[i] = fmt.Sprintf("tool_error: identical call to %s already made %d times this turn; use the earlier result instead of calling it again", toolCall.Function.Name, c.seen[key]-1)
+			continue
+		}
+
+		if c.toolCalls >= c.budget.MaxToolCalls {
+			rejections[i] = fmt.Sprintf("tool_error: tool call budget exhausted (%d calls per turn)", c.budget.MaxToolCalls)
+			continue
+		}
+		c.toolCalls++
+		admitted = append(admitted, i)
+	}
+
+	// the model is looping on the same calls: stop offering tools
+	if repeats > 0 && len(admitted) == 0 && c.reason == "" {
+		c.reason = "the model kept repeating identical tool calls"
+		slog.Warn("turn budget exhausted", "reason", c.reason, "iterations", c.iterations, "tool_calls", c.toolCalls)
+	}
+	return admitted, rejections
+}
+
+// finalAnswerParams turns param into a tool-free request asking the model to answer with what it has.
+// The instruction is only added to this request, not to History.
+func (c *loopController) finalAnswerParams(param openai.ChatCompletionNewParams, history []openai.ChatCompletionMessageParamUnion) openai.ChatCompletionNewParams {
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(history)+1)
+	messages = append(messages, history...)
+	messages = append(messages, openai.SystemMessage(fmt.Sprintf(finalAnswerInstruction, c.reason)))
+	param.Messages = messages
+	if len(param.Tools) == 0 {
+		return param // tool_choice is rejected on requests without tools
+	}
+	param.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(string(openai.ChatCompletionToolChoiceOptionAutoNone))}
+	return param
+}
+
+// toolCallFingerprint identifies a call by tool name and canonicalized arguments.
+func toolCallFingerprint(toolCall openai.ChatCompletionMessageToolCallUnion) string {
+	args := toolCall.Function.Arguments
+	var parsed any
+	if err := json.Unmarshal([]byte(args), &parsed); err == nil {
+		// re-marshal so key order and whitespace don't matter
+		if b, err := json.Marshal(parsed); err == nil {
+			args = string(b)
+		}
+	}
+	return toolCall.Function.Name + "\x00" + args
+}
@@ -0,0 +1,42 @@
+package send_receive
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/openai/openai-go/v2/packages/respjson"
+)
+
+// reasoningPrefix marks reasoning summaries in the REPL
+const reasoningPrefix = "💭 "
+
+// reasoningFields are the fields some OpenAI-compatible servers (DeepSeek, vLLM, OpenRouter, ...) use
+// for reasoning summaries. They are not part of Chat Completions: OpenAI itself never sends them and
+// only reports the count in usage.completion_tokens_details.reasoning_tokens.
+var reasoningFields = []string{"reasoning_content", "reasoning"}
+
+// reasoningText returns the reasoning summary carried by a message or stream delta, or "".
+// It is only consulted when OpenAIConfig.ShowReasoning is set, since other servers may use these
+// names for something else.
+func reasoningText(show bool, extra map[string]respjson.Field) string {
+	if !show {
+		return ""
+	}
+	for _, key := range reasoningFields {
+		// extra fields are never "valid" (the SDK has no type for them); the raw JSON is kept
+		field, ok := extra[key]
+		if !ok {
+			continue
+		}
+		var text string
+		if json.Unmarshal([]byte(field.Raw()), &text) == nil && text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// formatReasoning renders a reasoning summary to show above the answer.
+func formatReasoning(reasoning string) string {
+	return reasoningPrefix + strings.TrimSpace(reasoning) + "\n\n"
+}
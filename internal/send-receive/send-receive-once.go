@@ -2,15 +2,13 @@ package send_receive
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"runtime/debug"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/openai/openai-go/v2"
 	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
 	client_openai "github.com/pavitra93/11-openai-chats/external/clients/openai"
@@ -19,6 +17,7 @@ import (
 type StrategyOnce struct {
 	OpenAIConfig *client_openai.OpenAIConfig
 	MCPManager   *client_mcp.Manager
+	turnControl
 }
 
 func NewOnceStrategy(config *client_openai.OpenAIConfig, mcpManager *client_mcp.Manager) *StrategyOnce {
@@ -58,32 +57,45 @@ func (w *StrategyOnce) SendtoOpenAI(ctx context.Context, messages <-chan string,
 
 			slog.Info("received user message", "req", reqID, "step", next(), "message", message)
 
+			// per-turn context: cancelled by Ctrl-C, which also cancels in-flight MCP tool calls
+			turnCtx, endTurn := w.beginTurn(ctx)
+
 			// Construct the common params
-			param := &openai.ChatCompletionNewParams{
-				Model:       openai.ChatModelGPT4_1,
-				Seed:        openai.Int(0),
-				Temperature: openai.Float(w.OpenAIConfig.Temperature),
-			}
+			chatParams := w.OpenAIConfig.NewChatParams()
+			param := &chatParams
 
-			var toolCollection = make([]openai.ChatCompletionToolUnionParam, 0)
-			for _, tool := range w.MCPManager.GetAllSchemas() {
-				toolCollection = append(toolCollection, tool...)
-			}
-			param.Tools = toolCollection
-			slog.Info("tools assembled", "req", reqID, "step", next(), "tools_count", len(toolCollection))
+			setRequestTools(param, w.OpenAIConfig, w.MCPManager, reqID)
+			slog.Info("tools assembled", "req", reqID, "step", next(), "tools_count", len(param.Tools))
 
 			// append user message
 			w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, openai.UserMessage(message))
 			slog.Info("history appended user", "req", reqID, "step", next(), "history_len", len(w.OpenAIConfig.History.Messages))
 
+			// bounds the tool-calling loop of this turn
+			budget := newLoopController(w.OpenAIConfig.Budget)
+
 		iterate:
 
 			param.Messages = w.OpenAIConfig.History.Messages
 
+			// once a budget is exhausted, ask for a final answer without tools
+			request := *param
+			finalAnswer := budget.exhausted() != ""
+			if finalAnswer {
+				request = budget.finalAnswerParams(request, w.OpenAIConfig.History.Messages)
+				slog.Info("requesting final answer", "req", reqID, "step", next(), "reason", budget.exhausted())
+			}
+
 			// Send the request (use ctx)
 			slog.Info("sending completion request", "req", reqID, "step", next())
-			resp, err := w.OpenAIConfig.OpenAPIClient.Chat.Completions.New(ctx, *param)
+			resp, err := w.OpenAIConfig.Provider.Complete(turnCtx, request)
 			if err != nil {
+				endTurn()
+				if ctx.Err() == nil && errors.Is(err, context.Canceled) {
+					slog.Info("turn cancelled by user", "req", reqID, "step", next())
+					reciever <- "Turn cancelled."
+					continue
+				}
 				slog.Error("completion request failed", "req", reqID, "step", step, "error", err)
 				reciever <- "Error: " + err.Error()
 				return
@@ -91,23 +103,33 @@ func (w *StrategyOnce) SendtoOpenAI(ctx context.Context, messages <-chan string,
 
 			// safety: ensure we have at least one choice
 			if len(resp.Choices) == 0 {
-				reciever <- "Error: " + err.Error()
+				endTurn()
+				reciever <- "Error: completion returned no choices"
 				return
 			}
 
+			budget.recordCompletion(reqID, resp.Usage)
 			choice := resp.Choices[0]
 			toolCalls := choice.Message.ToolCalls
 			slog.Info("received tool calls", "req", reqID, "step", next(), "count", len(toolCalls))
 
 			// If there are no tools calls, it's a regular assistant response.
-			if len(toolCalls) == 0 {
+			// The final answer is delivered as-is even if the model still asked for tools.
+			if len(toolCalls) == 0 || finalAnswer {
 				if len(choice.Message.Content) > 0 && w.OpenAIConfig.AllowHistory {
-					// append assistant message to history
-					w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, choice.Message.ToParam())
+					// append assistant message to history (without unanswerable tool calls)
+					w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, openai.AssistantMessage(choice.Message.Content))
 				}
 
 				// send messages back to channel
-				reciever <- choice.Message.Content
+				budget.logUsage(reqID)
+				endTurn()
+				reply := choice.Message.Content
+				if reasoning := reasoningText(w.OpenAIConfig.ShowReasoning, choice.Message.JSON.ExtraFields); reasoning != "" {
+					// the summary is shown but not kept in history
+					reply = formatReasoning(reasoning) + reply
+				}
+				reciever <- reply
 				slog.Info("assistant message delivered", "req", reqID, "step", next())
 			} else {
 				// **Important**: append the assistant message that *requested* the tools call
@@ -115,108 +137,43 @@ func (w *StrategyOnce) SendtoOpenAI(ctx context.Context, messages <-chan string,
 					w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, choice.Message.ToParam())
 				}
 
-				for _, toolCall := range toolCalls {
+				// run the admitted tools concurrently; answers keep the original tool_call order
+				outcomes := executeWithinBudget(turnCtx, w.MCPManager, reqID, toolCalls, budget, w.OpenAIConfig.MaxParallelToolCalls, nil)
+				slog.Info("tool calls completed", "req", reqID, "step", next(), "count", len(outcomes))
 
-					// sometimes toolCall.Type may be "function" or you can check toolCall.Function != nil
-					if toolCall.Type != "function" {
+				toolImages := make([]openai.ChatCompletionContentPartUnionParam, 0)
+				for i, outcome := range outcomes {
+					if outcome.Skipped {
 						continue
 					}
+					toolImages = append(toolImages, outcome.Images...)
 
-					// Debug: Log the raw arguments string
-					slog.Info("tool args raw", "req", reqID, "step", next(), "tool", toolCall.Function.Name, "len", len(toolCall.Function.Arguments))
-
-					// 1) Parse the JSON-encoded arguments string into a map
-					var args map[string]any
-					err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
-					if err != nil {
-						slog.Error("failed to parse tool args", "req", reqID, "step", step, "tool", toolCall.Function.Name, "error", err)
-						slog.Info("tool args raw copy", "req", reqID, "step", step, "raw", toolCall.Function.Arguments)
-
-						// Try to fix common JSON truncation issues
-						argsStr := toolCall.Function.Arguments
-						if !strings.HasSuffix(argsStr, "}") && !strings.HasSuffix(argsStr, "]") {
-							slog.Warn("args appear truncated; attempting fix", "req", reqID, "step", next())
-
-							// For Notion API calls, try to create a simpler structure
-							if strings.Contains(toolCall.Function.Name, "notion") && strings.Contains(argsStr, "\"content\":\"") {
-								// Extract the title and create a simple page structure
-								titleStart := strings.Index(argsStr, "\"title\":[{\"text\":{\"content\":\"")
-								if titleStart > 0 {
-									titleStart += len("\"title\":[{\"text\":{\"content\":\"")
-									titleEnd := strings.Index(argsStr[titleStart:], "\"")
-									if titleEnd > 0 {
-										title := argsStr[titleStart : titleStart+titleEnd]
-
-										// Create a simplified page structure
-										argsStr = fmt.Sprintf(`{"parent":{"page_id":"ca42c764-61c4-45f6-9aaf-22910ec57800"},"properties":{"title":[{"text":{"content":"%s"}}]}}`, title)
-										slog.Info("created simplified args", "req", reqID, "step", next())
-										err = json.Unmarshal([]byte(argsStr), &args)
-										if err != nil {
-											slog.Error("simplified args parse failed", "req", reqID, "step", step, "error", err)
-										}
-									}
-								}
-							} else {
-								// Generic fix attempt
-								lastQuote := strings.LastIndex(argsStr, "\"")
-								if lastQuote > 0 {
-									argsStr = argsStr[:lastQuote+1] + "]}}}"
-									slog.Info("attempting generic fix parse", "req", reqID, "step", next())
-									err = json.Unmarshal([]byte(argsStr), &args)
-									if err != nil {
-										slog.Error("generic fix parse failed", "req", reqID, "step", step, "error", err)
-									}
-								}
-							}
-						}
-
-						if err != nil {
-							// append an error tool message back to history so API sees we responded
-							if w.OpenAIConfig.AllowHistory {
-								errMsg := fmt.Sprintf("error_parsing_args: %v", err)
-								w.OpenAIConfig.History.Messages = append(
-									w.OpenAIConfig.History.Messages,
-									openai.ToolMessage(errMsg, toolCall.ID),
-								)
-							}
-							continue
-						}
-					}
-
-					// 2) Build CallToolParams (use field names - adjust if your SDK has different fields)
-					params := &mcp.CallToolParams{
-						Name:      toolCall.Function.Name,
-						Arguments: args,
-					}
-					slog.Info("calling MCP tool", "req", reqID, "step", next(), "tool", params.Name)
-
-					// Debug: Log the parsed arguments structure
-					argsBytes, _ := json.Marshal(args)
-					slog.Debug("parsed args json", "req", reqID, "step", step, "json", string(argsBytes))
-
-					// 3) Call the MCP tool and check error
-					respStr, err := w.MCPManager.CallTool(toolCall.ID, toolCall.Function.Name, args)
-					if err != nil {
-						slog.Error("CallTool error", "req", reqID, "step", step, "tool", params.Name, "error", err)
-						if w.OpenAIConfig.AllowHistory {
-							w.OpenAIConfig.History.Messages = append(
-								w.OpenAIConfig.History.Messages,
-								openai.ToolMessage(fmt.Sprintf("tool_error: %v", err), toolCall.ID),
-							)
-						}
-						continue
-					}
-
-					slog.Info("tool call response", "req", reqID, "step", next(), "tool", params.Name)
-
-					// 5) Append tool response to conversation history (must follow the assistant message)
+					// Append tool response to conversation history (must follow the assistant message)
 					if w.OpenAIConfig.AllowHistory {
 						w.OpenAIConfig.History.Messages = append(
 							w.OpenAIConfig.History.Messages,
-							openai.ToolMessage(respStr, toolCall.ID),
+							openai.ToolMessage(outcome.Content, toolCalls[i].ID),
 						)
 					}
+				}
+
+				if turnCtx.Err() != nil {
+					endTurn()
+					if ctx.Err() != nil {
+						return
+					}
+					slog.Info("turn cancelled by user during tool calls", "req", reqID, "step", next())
+					reciever <- "Turn cancelled."
+					continue
+				}
 
+				// Tool messages only carry text: hand images to vision models on a follow-up user message
+				if len(toolImages) > 0 && w.OpenAIConfig.AllowHistory && client_openai.SupportsVision(param.Model) {
+					parts := append([]openai.ChatCompletionContentPartUnionParam{
+						openai.TextContentPart("Images returned by the tool calls above:"),
+					}, toolImages...)
+					w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, openai.UserMessage(parts))
+					slog.Info("tool images attached", "req", reqID, "step", next(), "count", len(toolImages))
 				}
 
 				goto iterate
@@ -3,10 +3,12 @@ package send_receive
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/openai/openai-go/v2"
 	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
@@ -17,6 +19,7 @@ import (
 type StreamStrategy struct {
 	OpenAIConfig *openai_client.OpenAIConfig
 	MCPManager   *client_mcp.Manager
+	turnControl
 }
 
 func NewStreamStrategy(config *openai_client.OpenAIConfig, mcpManager *client_mcp.Manager) *StreamStrategy {
@@ -28,6 +31,10 @@ func NewStreamStrategy(config *openai_client.OpenAIConfig, mcpManager *client_mc
 
 func (w *StreamStrategy) SendtoOpenAI(ctx context.Context, messages <-chan string, reciever chan<- string, wg *sync.WaitGroup) {
 	defer wg.Done()
+
+	// per-run correlation id for tool call logs
+	reqID := fmt.Sprintf("stream-%d", time.Now().UnixNano())
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -41,64 +48,195 @@ func (w *StreamStrategy) SendtoOpenAI(ctx context.Context, messages <-chan strin
 
 			// make history window and append user message
 			w.OpenAIConfig.History.Messages = utils.MakeHistoryWindow(w.OpenAIConfig.History.Messages, message, w.OpenAIConfig.HistorySize)
+			w.OpenAIConfig.History.Messages = dropOrphanToolMessages(w.OpenAIConfig.History.Messages)
 			w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, openai.UserMessage(message))
 			slog.Info("History window created", "History", w.OpenAIConfig.History.Messages)
 
 			// send messages to OpenAI
-			param := openai.ChatCompletionNewParams{
-				Messages:    w.OpenAIConfig.History.Messages,
-				Model:       openai.ChatModelGPT4_1,
-				MaxTokens:   openai.Int(w.OpenAIConfig.MaxTokens),
-				Temperature: openai.Float(w.OpenAIConfig.Temperature),
-			}
+			param := w.OpenAIConfig.NewChatParams()
+			// usage is only reported on streams when requested; the loop budget counts tokens
+			param.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
 
-			acc := openai.ChatCompletionAccumulator{}
+			setRequestTools(&param, w.OpenAIConfig, w.MCPManager, reqID)
+			slog.Info("tools assembled", "tools_count", len(param.Tools))
 
-			// Send the request
-			stream := w.OpenAIConfig.OpenAPIClient.Chat.Completions.NewStreaming(context.TODO(), param)
-			for stream.Next() {
-				chunk := stream.Current()
+			// per-turn context so Ctrl-C can stop the stream and in-flight tool calls
+			turnCtx, endTurn := w.beginTurn(ctx)
 
-				acc.AddChunk(chunk)
+			// one reply per user message: tool rounds stream into the same reply
+			reciever <- "stream:start"
 
-				// When this fires, the current chunk value will not contain content data
-				if justCompleted, ok := acc.JustFinishedContent(); ok {
-					slog.Info("Streaming Just Completed", "Message", justCompleted)
-					reciever <- "stream:completed"
+			// bounds the tool-calling loop of this turn
+			budget := newLoopController(w.OpenAIConfig.Budget)
+
+			for {
+				param.Messages = w.OpenAIConfig.History.Messages
+
+				// once a budget is exhausted, ask for a final answer without tools
+				request := param
+				finalAnswer := budget.exhausted() != ""
+				if finalAnswer {
+					request = budget.finalAnswerParams(request, w.OpenAIConfig.History.Messages)
+					slog.Info("requesting final answer", "req", reqID, "reason", budget.exhausted())
 				}
 
-				// It's best to use chunks after handling JustFinished events.
-				// Here we print the delta of the content, if it exists.
-				if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-					// send messages back to channel
-					reciever <- chunk.Choices[0].Delta.Content
+				acc, err := w.streamCompletion(turnCtx, request, reciever)
+				if err != nil {
+					slog.Error("Error streaming response from OpenAI.",
+						slog.Group("error",
+							slog.String("message", err.Error()),
+						))
+
+					// finish the reply so the REPL is released
+					if turnCtx.Err() != nil {
+						reciever <- " [cancelled]"
+					} else {
+						reciever <- "Error: " + err.Error()
+					}
+					break
 				}
-			}
 
-			if err := stream.Err(); err != nil {
-				slog.Error("Error streaming response from OpenAI.",
-					slog.Group("error",
-						slog.String("message", err.Error()),
-					))
-			}
+				budget.recordCompletion(reqID, acc.Usage)
 
-			if acc.Usage.TotalTokens > 0 {
-				slog.Info("Streaming finished with usage", "Token Usage", acc.Usage.TotalTokens)
-			}
+				// safety: ensure we have at least one choice
+				if len(acc.Choices) == 0 {
+					reciever <- "Error: completion returned no choices"
+					break
+				}
+
+				choice := acc.Choices[0]
+				slog.Info("Response from OpenAI", "Content", choice.Message.Content, "finish reason", choice.FinishReason, "tool_calls", len(choice.Message.ToolCalls))
+
+				// no tool calls: the streamed text is the final answer
+				if len(choice.Message.ToolCalls) == 0 || finalAnswer {
+					if len(choice.Message.Content) > 0 && w.OpenAIConfig.AllowHistory {
+						w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, openai.AssistantMessage(choice.Message.Content))
+					}
+					break
+				}
+
+				// the assistant message that requested the tools must precede the tool results
+				if w.OpenAIConfig.AllowHistory {
+					w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, choice.Message.ToParam())
+				}
+				if choice.Message.Content != "" {
+					reciever <- "\n"
+				}
+
+				// run the admitted tools concurrently, reporting progress between the streamed segments
+				toolCalls := choice.Message.ToolCalls
+				progress := func(line string) { reciever <- line }
+				outcomes := executeWithinBudget(turnCtx, w.MCPManager, reqID, toolCalls, budget, w.OpenAIConfig.MaxParallelToolCalls, progress)
+
+				toolImages := make([]openai.ChatCompletionContentPartUnionParam, 0)
+				for i, outcome := range outcomes {
+					if outcome.Skipped {
+						continue
+					}
+					toolImages = append(toolImages, outcome.Images...)
+					if w.OpenAIConfig.AllowHistory {
+						w.OpenAIConfig.History.Messages = append(
+							w.OpenAIConfig.History.Messages,
+							openai.ToolMessage(outcome.Content, toolCalls[i].ID),
+						)
+					}
+				}
 
-			slog.Info("Response from OpenAI", "Content", acc.Choices[0].Message.Content, "finish reason", acc.Choices[0].FinishReason)
+				if turnCtx.Err() != nil {
+					slog.Info("turn cancelled by user during tool calls")
+					reciever <- "[cancelled]"
+					break
+				}
 
-			// Safely print the first text part if the SDK returns structured content
-			if len(acc.Choices[0].Message.Content) > 0 && len(acc.Choices[0].Message.Content) > 0 && w.OpenAIConfig.AllowHistory {
-				w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, acc.Choices[0].Message.ToParam())
+				// tool messages only carry text: hand images to vision models on a follow-up user message
+				if len(toolImages) > 0 && w.OpenAIConfig.AllowHistory && openai_client.SupportsVision(param.Model) {
+					parts := append([]openai.ChatCompletionContentPartUnionParam{
+						openai.TextContentPart("Images returned by the tool calls above:"),
+					}, toolImages...)
+					w.OpenAIConfig.History.Messages = append(w.OpenAIConfig.History.Messages, openai.UserMessage(parts))
+					slog.Info("tool images attached", "count", len(toolImages))
+				}
 			}
 
+			budget.logUsage(reqID)
+			endTurn()
+			reciever <- "stream:completed"
 		}
 
 	}
 
 }
 
+// streamCompletion streams one completion, forwarding text deltas to the reciever,
+// and returns the accumulated message including any tool calls.
+func (w *StreamStrategy) streamCompletion(ctx context.Context, param openai.ChatCompletionNewParams, reciever chan<- string) (*openai.ChatCompletionAccumulator, error) {
+	acc := &openai.ChatCompletionAccumulator{}
+
+	stream := w.OpenAIConfig.Provider.Stream(ctx, param)
+	defer stream.Close()
+	thinking := false // streaming a reasoning summary
+	for stream.Next() {
+		chunk := stream.Current()
+
+		acc.AddChunk(chunk)
+		// the accumulator only sums the token totals; keep the details of the usage chunk (cached tokens)
+		if chunk.JSON.Usage.Valid() {
+			acc.Usage.PromptTokensDetails = chunk.Usage.PromptTokensDetails
+			acc.Usage.CompletionTokensDetails = chunk.Usage.CompletionTokensDetails
+		}
+
+		// When this fires, the current chunk value will not contain content data
+		if justCompleted, ok := acc.JustFinishedContent(); ok {
+			slog.Info("Streaming Just Completed", "Message", justCompleted)
+		}
+		if tool, ok := acc.JustFinishedToolCall(); ok {
+			slog.Info("Streaming tool call completed", "tool", tool.Name, "index", tool.Index)
+		}
+
+		// It's best to use chunks after handling JustFinished events.
+		// Here we print the delta of the reasoning summary and the content, if they exist.
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		if reasoning := reasoningText(w.OpenAIConfig.ShowReasoning, delta.JSON.ExtraFields); reasoning != "" {
+			if !thinking {
+				reciever <- reasoningPrefix
+				thinking = true
+			}
+			reciever <- reasoning
+		} else if thinking && (delta.Content != "" || len(delta.ToolCalls) > 0) {
+			// the answer starts below the reasoning summary
+			reciever <- "\n\n"
+			thinking = false
+		}
+		if delta.Content != "" {
+			// send messages back to channel
+			reciever <- delta.Content
+		}
+	}
+	if thinking {
+		reciever <- "\n"
+	}
+	return acc, stream.Err()
+}
+
+// dropOrphanToolMessages removes tool messages at the start of the window whose
+// assistant tool_calls message was cut off by MakeHistoryWindow; the API rejects them.
+func dropOrphanToolMessages(history []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	if len(history) < 2 {
+		return history
+	}
+	i := 1 // keep the system message
+	for i < len(history) && history[i].OfTool != nil {
+		i++
+	}
+	if i == 1 {
+		return history
+	}
+	return append(history[:1:1], history[i:]...)
+}
+
 func (w *StreamStrategy) RecieveFromOpenAI(ctx context.Context, reciever <-chan string, done chan<- bool, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -8,4 +8,6 @@ import (
 type SendAndRecieveOpenAIStrategy interface {
 	SendtoOpenAI(ctx context.Context, messages <-chan string, reciever chan<- string, wg *sync.WaitGroup)
 	RecieveFromOpenAI(ctx context.Context, reciever <-chan string, done chan<- bool, wg *sync.WaitGroup)
+	// CancelTurn cancels the in-flight user turn; false when idle
+	CancelTurn() bool
 }
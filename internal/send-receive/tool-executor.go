@@ -0,0 +1,204 @@
+package send_receive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+	client_openai "github.com/pavitra93/11-openai-chats/external/clients/openai"
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultMaxParallelToolCalls bounds concurrent tool calls when the config leaves it unset
+const defaultMaxParallelToolCalls = 4
+
+// setRequestTools adds the MCP tools to a request, in a stable order that keeps the prompt prefix
+// cacheable. Providers without native tool calling get no tools and answer from the conversation alone.
+func setRequestTools(param *openai.ChatCompletionNewParams, cfg *client_openai.OpenAIConfig, manager *client_mcp.Manager, reqID string) {
+	tools := manager.GetAllTools()
+	if len(tools) == 0 {
+		return
+	}
+	if !cfg.Provider.Capabilities().NativeTools {
+		slog.Info("provider has no native tool calling; request sent without tools", "req", reqID, "provider", cfg.Provider.Name(), "tools_count", len(tools))
+		return
+	}
+	param.Tools = tools
+	// parallel_tool_calls is rejected on requests without tools
+	if cfg.ParallelToolCalls != nil {
+		param.ParallelToolCalls = openai.Bool(*cfg.ParallelToolCalls)
+	}
+}
+
+// toolOutcome is the answer to one tool call, ready to be appended to History.
+type toolOutcome struct {
+	Content string
+	Images  []openai.ChatCompletionContentPartUnionParam
+	Skipped bool // not a function call; nothing to answer
+}
+
+// executeToolCalls runs the tool calls of one assistant message concurrently, at most maxParallel
+// at a time (per-server limits are enforced by the Manager), and returns the outcomes in the
+// original tool_call order. progress, when set, receives human readable status lines.
+func executeToolCalls(ctx context.Context, manager *client_mcp.Manager, reqID string, toolCalls []openai.ChatCompletionMessageToolCallUnion, maxParallel int, progress func(string)) []toolOutcome {
+	if maxParallel <= 0 {
+		maxParallel = defaultMaxParallelToolCalls
+	}
+	if progress == nil {
+		progress = func(string) {}
+	}
+
+	outcomes := make([]toolOutcome, len(toolCalls))
+	var g errgroup.Group
+	g.SetLimit(maxParallel)
+	for i, toolCall := range toolCalls {
+		g.Go(func() error {
+			outcomes[i] = runToolCall(ctx, manager, reqID, toolCall, progress)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return outcomes
+}
+
+// runToolCall parses the arguments of a single tool call and executes it through the MCP manager.
+func runToolCall(ctx context.Context, manager *client_mcp.Manager, reqID string, toolCall openai.ChatCompletionMessageToolCallUnion, progress func(string)) toolOutcome {
+	// only function tools are offered; other call types are left unanswered
+	if toolCall.Type != "function" {
+		return toolOutcome{Skipped: true}
+	}
+	name := toolCall.Function.Name
+
+	// cancelled turn: still answer every tool call so History stays valid for the next request
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return toolOutcome{Content: "tool_error: not run, the turn's time budget is exhausted"}
+	}
+	if ctx.Err() != nil {
+		return toolOutcome{Content: "tool_error: cancelled by user"}
+	}
+
+	slog.Info("tool args raw", "req", reqID, "tool", name, "len", len(toolCall.Function.Arguments))
+
+	// 1) Parse, repair and validate the JSON-encoded arguments
+	args, argsErr := parseToolArgs(manager, reqID, name, toolCall.Function.Arguments)
+	if argsErr != nil {
+		progress(fmt.Sprintf("❌ %s: invalid arguments\n", name))
+		return toolOutcome{Content: argsErr.String()}
+	}
+
+	argsBytes, _ := json.Marshal(args)
+	slog.Debug("parsed args json", "req", reqID, "tool", name, "json", string(argsBytes))
+
+	// 2) Call the MCP tool and check error
+	progress(fmt.Sprintf("🔧 Calling %s...\n", name))
+	start := time.Now()
+	result, err := manager.CallTool(ctx, toolCall.ID, name, args)
+	if err != nil {
+		slog.Error("CallTool error", "req", reqID, "tool", name, "error", err)
+		progress(fmt.Sprintf("❌ %s failed\n", name))
+		return toolOutcome{Content: fmt.Sprintf("tool_error: %v", err)}
+	}
+
+	elapsed := time.Since(start)
+	slog.Info("tool call response", "req", reqID, "tool", name, "is_error", result.IsError, "duration", elapsed)
+
+	// 3) Surface tool-reported failures explicitly so the model doesn't treat them as data
+	out := toolOutcome{Content: result.Text}
+	if result.IsError {
+		out.Content = "tool_error: " + out.Content
+		progress(fmt.Sprintf("❌ %s failed\n", name))
+	} else {
+		progress(fmt.Sprintf("✅ %s done (%s)\n", name, elapsed.Round(time.Millisecond)))
+	}
+	for _, img := range result.Images {
+		out.Images = append(out.Images, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: img.DataURL}))
+	}
+	return out
+}
+
+// argsError is the structured tool message returned when arguments can't be used,
+// so the model can correct them and retry.
+type argsError struct {
+	Error   string                `json:"error"`
+	Tool    string                `json:"tool"`
+	Message string                `json:"message"`
+	Issues  []client_mcp.ArgIssue `json:"issues,omitempty"`
+}
+
+func (e argsError) String() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+// parseToolArgs decodes the model's JSON arguments, repairing truncated or sloppy JSON when needed,
+// and validates them against the tool's input schema.
+func parseToolArgs(manager *client_mcp.Manager, reqID, name, raw string) (map[string]any, *argsError) {
+	var args map[string]any
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		repaired := utils.RepairJSON(raw)
+		slog.Warn("tool args are not valid JSON; attempting repair", "req", reqID, "tool", name, "error", err, "len", len(raw))
+		args = nil
+		if rerr := json.Unmarshal([]byte(repaired), &args); rerr != nil {
+			slog.Error("failed to parse tool args", "req", reqID, "tool", name, "error", rerr)
+			return nil, &argsError{
+				Error:   "invalid_json",
+				Tool:    name,
+				Message: fmt.Sprintf("arguments are not a valid JSON object: %v", err),
+			}
+		}
+		slog.Info("tool args repaired", "req", reqID, "tool", name)
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	if issues := manager.ValidateToolArgs(name, args); len(issues) > 0 {
+		slog.Warn("tool args failed validation", "req", reqID, "tool", name, "issues", len(issues))
+		return nil, &argsError{
+			Error:   "invalid_arguments",
+			Tool:    name,
+			Message: "arguments do not match the tool's input schema; fix the listed issues and call the tool again",
+			Issues:  issues,
+		}
+	}
+	return args, nil
+}
+
+// executeWithinBudget runs the tool calls the loop controller admits and answers the rest with
+// its rejection, so the outcomes still line up with toolCalls. Calls still running when the turn's
+// wall-clock budget runs out are cancelled.
+func executeWithinBudget(ctx context.Context, manager *client_mcp.Manager, reqID string, toolCalls []openai.ChatCompletionMessageToolCallUnion, budget *loopController, maxParallel int, progress func(string)) []toolOutcome {
+	admitted, rejections := budget.admit(toolCalls)
+
+	ctx, cancel := context.WithDeadline(ctx, budget.deadline())
+	defer cancel()
+
+	run := make([]openai.ChatCompletionMessageToolCallUnion, len(admitted))
+	for j, i := range admitted {
+		run[j] = toolCalls[i]
+	}
+	results := executeToolCalls(ctx, manager, reqID, run, maxParallel, progress)
+
+	outcomes := make([]toolOutcome, len(toolCalls))
+	for i, toolCall := range toolCalls {
+		if toolCall.Type != "function" {
+			outcomes[i] = toolOutcome{Skipped: true}
+		}
+	}
+	for i, msg := range rejections {
+		outcomes[i] = toolOutcome{Content: msg}
+		if progress != nil {
+			progress(fmt.Sprintf("⛔ %s skipped\n", toolCalls[i].Function.Name))
+		}
+	}
+	for j, i := range admitted {
+		outcomes[i] = results[j]
+	}
+	return outcomes
+}
@@ -0,0 +1,38 @@
+package send_receive
+
+import (
+	"context"
+	"sync"
+)
+
+// turnControl tracks the in-flight user turn so the REPL can cancel it (Ctrl-C)
+// without tearing down the sender goroutine.
+type turnControl struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// beginTurn derives the context of a new user turn; call the returned func when the turn ends.
+func (t *turnControl) beginTurn(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	t.mu.Lock()
+	t.cancel = cancel
+	t.mu.Unlock()
+	return ctx, func() {
+		t.mu.Lock()
+		t.cancel = nil
+		t.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelTurn cancels the in-flight turn. It reports false when no turn is running.
+func (t *turnControl) CancelTurn() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel == nil {
+		return false
+	}
+	t.cancel()
+	return true
+}
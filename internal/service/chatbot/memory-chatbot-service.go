@@ -1,19 +1,24 @@
 package chatbot
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
 
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+	client_openai "github.com/pavitra93/11-openai-chats/external/clients/openai"
 	"github.com/pavitra93/11-openai-chats/internal/send-receive"
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
 )
 
 type MemoryChatbotService struct {
 	SenderStrategy send_receive.SendAndRecieveOpenAIStrategy
+	MCPManager     *client_mcp.Manager
+	OpenAIConfig   *client_openai.OpenAIConfig
 }
 
 func (m *MemoryChatbotService) RunMemoryChatbot() {
@@ -41,14 +46,30 @@ func (m *MemoryChatbotService) RunMemoryChatbot() {
 	go m.SenderStrategy.SendtoOpenAI(ctx, JobMessages, ReceiveMessages, wg)
 	go m.SenderStrategy.RecieveFromOpenAI(ctx, ReceiveMessages, doneChan, wg)
 
-	// initialize reader
-	reader := bufio.NewReader(os.Stdin)
+	// Ctrl-C cancels the running turn (model request and MCP tool calls); when idle it exits
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	defer signal.Stop(sigChan)
+	go func() {
+		for range sigChan {
+			if m.SenderStrategy.CancelTurn() {
+				fmt.Println("\nCancelling...")
+				continue
+			}
+			fmt.Println("\nBye. Thanks for chatting with me.")
+			slog.Info("Chat interrupted by user")
+			os.Exit(130)
+		}
+	}()
+
+	// slash commands (/resources, /attach, /<server>:<prompt>, ...)
+	commands := &replCommands{MCPManager: m.MCPManager, OpenAIConfig: m.OpenAIConfig}
 
 	// start chat loop
 	for {
 		dispatched := false
 		fmt.Print("🧔🏻‍♂️ You: ")
-		userMessage, _ := reader.ReadString('\n')
+		userMessage, _ := utils.ReadLine(ctx)
 		userMessage = strings.TrimSpace(userMessage)
 		slog.Info(userMessage)
 
@@ -73,6 +94,15 @@ func (m *MemoryChatbotService) RunMemoryChatbot() {
 			slog.Info("Chat explicitly stopped by user")
 			return
 		default:
+			if strings.HasPrefix(userMessage, "/") {
+				handled, dispatch := commands.handle(ctx, userMessage)
+				if handled && dispatch == "" {
+					continue
+				}
+				if handled {
+					userMessage = dispatch
+				}
+			}
 			JobMessages <- userMessage
 			slog.Info("Message sent to sender channel")
 			dispatched = true
@@ -1,15 +1,14 @@
 package chatbot
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
 	"strings"
 	"sync"
 
 	"github.com/pavitra93/11-openai-chats/internal/send-receive"
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
 )
 
 type NoMemoryChatbotService struct {
@@ -40,14 +39,11 @@ func (n *NoMemoryChatbotService) RunNoMemoryChatbot() {
 	go n.SenderStrategy.SendtoOpenAI(ctx, JobMessages, ReceiveMessages, wg)
 	go n.SenderStrategy.RecieveFromOpenAI(ctx, ReceiveMessages, doneChan, wg)
 
-	// initialize reader
-	reader := bufio.NewReader(os.Stdin)
-
 	// start chat loop
 	for {
 		dispatched := false
 		fmt.Print("🧔🏻‍♂️ You: ")
-		userMessage, _ := reader.ReadString('\n')
+		userMessage, _ := utils.ReadLine(ctx)
 		userMessage = strings.TrimSpace(userMessage)
 		slog.Info(userMessage)
 
@@ -0,0 +1,214 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+	client_openai "github.com/pavitra93/11-openai-chats/external/clients/openai"
+)
+
+const commandTimeout = 30 * time.Second
+
+// replCommands handles the slash commands of the chat REPL.
+type replCommands struct {
+	MCPManager   *client_mcp.Manager
+	OpenAIConfig *client_openai.OpenAIConfig
+
+	// lastResources keeps the last /resources listing so /attach can refer to entries by number
+	lastResources []client_mcp.ResourceInfo
+}
+
+// handle runs a slash command. It reports false when input is not a known command.
+// A non-empty dispatch is a user message the command wants sent to the model (e.g. an expanded prompt).
+func (r *replCommands) handle(ctx context.Context, input string) (handled bool, dispatch string) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return false, ""
+	}
+
+	// bounds the server round-trips of the command; prompts time each request separately
+	// so the time the user spends typing arguments is not counted
+	cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	switch fields[0] {
+	case "/help":
+		r.printHelp()
+	case "/resources":
+		r.listResources(cmdCtx, fields[1:])
+	case "/attach":
+		r.attachResource(cmdCtx, fields[1:])
+	case "/prompts":
+		r.listPrompts()
+	case "/models":
+		r.listModels(cmdCtx)
+	case "/model":
+		r.setModel(fields[1:])
+	case "/set":
+		r.setGeneration(fields[1:])
+	default:
+		server, prompt, ok := strings.Cut(strings.TrimPrefix(fields[0], "/"), ":")
+		if !ok || r.MCPManager == nil || r.MCPManager.FindPrompt(server, prompt) == nil {
+			return false, ""
+		}
+		return true, r.runPrompt(ctx, server, prompt, fields[1:])
+	}
+	return true, ""
+}
+
+func (r *replCommands) printHelp() {
+	fmt.Println("Commands:")
+	fmt.Println("  /resources [server]        list MCP resources")
+	fmt.Println("  /attach <n>                attach resource #n from the last /resources listing")
+	fmt.Println("  /attach <server> <uri>     attach a resource by URI")
+	fmt.Println("  /prompts                   list MCP prompts")
+	fmt.Println("  /<server>:<prompt> [k=v]   run an MCP prompt; missing arguments are asked for")
+	fmt.Println("  /models                    list the models of the LLM provider")
+	fmt.Println("  /model [name]              show or switch the chat model")
+	fmt.Println("  /set [key value]           show or change a generation setting (value \"default\" unsets it)")
+	fmt.Println("  exit | quit | bye          leave the chat")
+}
+
+func (r *replCommands) listResources(ctx context.Context, args []string) {
+	if r.MCPManager == nil {
+		fmt.Println("No MCP manager configured")
+		return
+	}
+
+	var resources []client_mcp.ResourceInfo
+	if len(args) > 0 {
+		var err error
+		resources, err = r.MCPManager.ListResources(ctx, args[0])
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	} else {
+		resources = r.MCPManager.ListAllResources(ctx)
+	}
+
+	r.lastResources = resources
+	if len(resources) == 0 {
+		fmt.Println("No resources available")
+		return
+	}
+	for i, res := range resources {
+		uri := res.URI
+		if uri == "" {
+			uri = res.URITemplate + " (template)"
+		}
+		fmt.Printf("  %d. [%s] %s - %s", i+1, res.Server, res.Name, uri)
+		if res.Description != "" {
+			fmt.Printf(" (%s)", res.Description)
+		}
+		fmt.Println()
+	}
+}
+
+func (r *replCommands) attachResource(ctx context.Context, args []string) {
+	if r.MCPManager == nil || r.OpenAIConfig == nil {
+		fmt.Println("No MCP manager configured")
+		return
+	}
+
+	var server, uri string
+	switch len(args) {
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(r.lastResources) {
+			fmt.Println("Usage: /attach <n> (run /resources first) or /attach <server> <uri>")
+			return
+		}
+		res := r.lastResources[n-1]
+		if res.URI == "" {
+			fmt.Println("Resource templates need a concrete URI: /attach <server> <uri>")
+			return
+		}
+		server, uri = res.Server, res.URI
+	case 2:
+		server, uri = args[0], args[1]
+	default:
+		fmt.Println("Usage: /attach <n> or /attach <server> <uri>")
+		return
+	}
+
+	text, err := r.MCPManager.ReadResourceText(ctx, server, uri)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	// the sender goroutine is idle between turns, so History can be extended here
+	attached := fmt.Sprintf("Context attached from MCP resource %s (server %s):\n\n%s", uri, server, text)
+	r.OpenAIConfig.History.Messages = append(r.OpenAIConfig.History.Messages, openai.UserMessage(attached))
+	slog.Info("resource attached to history", "server", server, "uri", uri, "len", len(text))
+	fmt.Printf("Attached %s (%d chars)\n", uri, len(text))
+}
+
+func (r *replCommands) listModels(ctx context.Context) {
+	if r.OpenAIConfig == nil || r.OpenAIConfig.Provider == nil {
+		fmt.Println("No LLM provider configured")
+		return
+	}
+	provider := r.OpenAIConfig.Provider
+	models, err := provider.ListModels(ctx)
+	if err != nil {
+		slog.Error("failed to list models", "provider", provider.Name(), "error", err)
+		fmt.Println("Error:", err)
+		return
+	}
+	sort.Strings(models)
+	fmt.Printf("Models of %s (current: %s):\n", provider.Name(), r.OpenAIConfig.CurrentModel())
+	for _, m := range models {
+		fmt.Println("  " + m)
+	}
+}
+
+func (r *replCommands) setModel(args []string) {
+	if r.OpenAIConfig == nil {
+		fmt.Println("No LLM configured")
+		return
+	}
+	if len(args) == 0 {
+		fmt.Println("Model:", r.OpenAIConfig.CurrentModel())
+		return
+	}
+	r.OpenAIConfig.SetModel(args[0])
+	slog.Info("model changed", "model", args[0])
+	fmt.Println("Model set to", args[0])
+}
+
+func (r *replCommands) setGeneration(args []string) {
+	if r.OpenAIConfig == nil {
+		fmt.Println("No LLM configured")
+		return
+	}
+	if len(args) == 0 {
+		settings := r.OpenAIConfig.GenerationSettings()
+		fmt.Println("Generation settings:")
+		for _, key := range client_openai.GenerationKeys {
+			fmt.Printf("  %-18s %s\n", key, settings.Get(key))
+		}
+		return
+	}
+	if len(args) < 2 {
+		fmt.Println("Usage: /set <key> <value> (keys: " + strings.Join(client_openai.GenerationKeys, ", ") + ")")
+		return
+	}
+
+	key, value := args[0], strings.Join(args[1:], " ")
+	if err := r.OpenAIConfig.SetGeneration(key, value); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	settings := r.OpenAIConfig.GenerationSettings()
+	slog.Info("generation setting changed", "key", key, "value", settings.Get(key))
+	fmt.Printf("%s set to %s\n", key, settings.Get(key))
+}
@@ -0,0 +1,151 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openai/openai-go/v2"
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+)
+
+// maxSuggestions caps how many completion values are shown per argument
+const maxSuggestions = 10
+
+func (r *replCommands) listPrompts() {
+	if r.MCPManager == nil {
+		fmt.Println("No MCP manager configured")
+		return
+	}
+	prompts := r.MCPManager.ListAllPrompts()
+	if len(prompts) == 0 {
+		fmt.Println("No prompts available")
+		return
+	}
+	for _, p := range prompts {
+		args := make([]string, 0, len(p.Prompt.Arguments))
+		for _, a := range p.Prompt.Arguments {
+			if a.Required {
+				args = append(args, a.Name)
+			} else {
+				args = append(args, "["+a.Name+"]")
+			}
+		}
+		fmt.Printf("  /%s:%s %s", p.Server, p.Prompt.Name, strings.Join(args, " "))
+		if p.Prompt.Description != "" {
+			fmt.Printf(" (%s)", p.Prompt.Description)
+		}
+		fmt.Println()
+	}
+}
+
+// runPrompt collects the prompt's arguments (inline key=value or interactively), expands it on
+// the server and appends the resulting messages to History. A trailing user message is returned
+// so the caller can send it to the model like a typed message.
+func (r *replCommands) runPrompt(ctx context.Context, server, name string, inline []string) string {
+	prompt := r.MCPManager.FindPrompt(server, name)
+
+	args := make(map[string]string)
+	for _, kv := range inline {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			fmt.Printf("Ignoring %q: arguments are key=value\n", kv)
+			continue
+		}
+		args[k] = v
+	}
+
+	for _, a := range prompt.Arguments {
+		if _, ok := args[a.Name]; ok {
+			continue
+		}
+		value, ok := r.askArgument(ctx, server, name, a, args)
+		if !ok {
+			fmt.Println("Prompt cancelled")
+			return ""
+		}
+		if value != "" {
+			args[a.Name] = value
+		}
+	}
+
+	getCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	res, err := r.MCPManager.GetPrompt(getCtx, server, name, args)
+	cancel()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return ""
+	}
+
+	messages := res.Messages
+	dispatch := ""
+	if n := len(messages); n > 0 && messages[n-1].Role == "user" {
+		dispatch = client_mcp.ContentToText(messages[n-1].Content)
+		messages = messages[:n-1]
+	}
+
+	// the sender goroutine is idle between turns, so History can be extended here
+	for _, msg := range messages {
+		text := client_mcp.ContentToText(msg.Content)
+		if msg.Role == "assistant" {
+			r.OpenAIConfig.History.Messages = append(r.OpenAIConfig.History.Messages, openai.AssistantMessage(text))
+		} else {
+			r.OpenAIConfig.History.Messages = append(r.OpenAIConfig.History.Messages, openai.UserMessage(text))
+		}
+	}
+	slog.Info("prompt expanded into history", "server", server, "prompt", name, "messages", len(messages), "dispatch", dispatch != "")
+
+	if dispatch == "" {
+		fmt.Printf("Prompt %s:%s added to the conversation\n", server, name)
+	}
+	return dispatch
+}
+
+// askArgument reads one prompt argument from the user, showing server-side completion
+// suggestions when available. Entering a suggestion's number selects it.
+func (r *replCommands) askArgument(ctx context.Context, server, prompt string, arg *mcp.PromptArgument, known map[string]string) (string, bool) {
+	completeCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	suggestions, err := r.MCPManager.CompletePromptArgument(completeCtx, server, prompt, arg.Name, "", known)
+	cancel()
+	if err != nil {
+		slog.Warn("prompt argument completion failed", "server", server, "prompt", prompt, "arg", arg.Name, "error", err)
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	label := arg.Name
+	if arg.Description != "" {
+		label += " - " + arg.Description
+	}
+	if arg.Required {
+		label += " (required)"
+	}
+
+	for {
+		if len(suggestions) > 0 {
+			for i, s := range suggestions {
+				fmt.Printf("    %d) %s\n", i+1, s)
+			}
+		}
+		fmt.Printf("  %s: ", label)
+		line, err := utils.ReadLine(ctx)
+		if err != nil {
+			return "", false
+		}
+		value := strings.TrimSpace(line)
+
+		if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= len(suggestions) {
+			value = suggestions[n-1]
+		}
+		if value == "" && arg.Required {
+			fmt.Println("  This argument is required")
+			continue
+		}
+		return value, true
+	}
+}
@@ -0,0 +1,43 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+)
+
+// maxPreviewLen caps how much of a sampling message is shown to the user
+const maxPreviewLen = 300
+
+// ApproveSampling shows an MCP sampling request in the terminal and asks the user to allow it.
+// Input errors count as a denial; only ctx ending is reported as an error.
+func ApproveSampling(ctx context.Context, server string, params *mcp.CreateMessageParams) (bool, error) {
+	fmt.Printf("\n🔐 MCP server %q wants to run an LLM completion (max %d tokens)\n", server, params.MaxTokens)
+	if params.SystemPrompt != "" {
+		fmt.Printf("   system: %s\n", preview(params.SystemPrompt))
+	}
+	for _, msg := range params.Messages {
+		fmt.Printf("   %s: %s\n", msg.Role, preview(client_mcp.ContentToText(msg.Content)))
+	}
+	approved, err := utils.Confirm(ctx, "   Allow?")
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err != nil {
+		slog.Warn("sampling approval input failed", "server", server, "error", err)
+	}
+	slog.Info("sampling approval", "server", server, "approved", approved)
+	return approved, nil
+}
+
+func preview(s string) string {
+	r := []rune(s)
+	if len(r) <= maxPreviewLen {
+		return s
+	}
+	return string(r[:maxPreviewLen]) + "…"
+}
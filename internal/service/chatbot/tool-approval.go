@@ -0,0 +1,82 @@
+package chatbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+	"github.com/pavitra93/11-openai-chats/pkg/utils"
+)
+
+// approvalMu keeps concurrent tool calls from interleaving their approval prompts
+var approvalMu sync.Mutex
+
+// ApproveToolCall shows a tool call in the terminal and asks the user to approve, deny or edit it.
+// Input errors count as a denial; only ctx ending (e.g. Ctrl-C on the turn) is reported as an error.
+func ApproveToolCall(ctx context.Context, req client_mcp.ToolApprovalRequest) (client_mcp.ToolApprovalDecision, error) {
+	approvalMu.Lock()
+	defer approvalMu.Unlock()
+
+	args := req.Args
+	for {
+		fmt.Printf("\n🛂 Tool call %s on MCP server %q\n", req.Tool, req.Server)
+		if req.Description != "" {
+			fmt.Printf("   %s\n", preview(req.Description))
+		}
+		if req.Annotations != nil && req.Annotations.DestructiveHint != nil && *req.Annotations.DestructiveHint {
+			fmt.Println("   ⚠️  the server marks this tool as destructive")
+		}
+		pretty, _ := json.MarshalIndent(args, "   ", "  ")
+		fmt.Printf("   arguments: %s\n", pretty)
+
+		answer, err := utils.Ask(ctx, "   [y]es / [a]lways / [n]o / ne[v]er / [e]dit arguments: ")
+		if ctx.Err() != nil {
+			return client_mcp.ToolApprovalDecision{}, ctx.Err()
+		}
+		if err != nil {
+			return client_mcp.ToolApprovalDecision{}, nil
+		}
+
+		decision := client_mcp.ToolApprovalDecision{Args: args}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			decision.Approved = true
+		case "a", "always":
+			decision.Approved, decision.Remember = true, true
+		case "n", "no", "":
+			decision.Approved = false
+		case "v", "never":
+			decision.Approved, decision.Remember = false, true
+		case "e", "edit":
+			if edited, ok := editArguments(ctx, args); ok {
+				args = edited
+			}
+			continue
+		default:
+			fmt.Println("   Please answer y, a, n, v or e.")
+			continue
+		}
+		slog.Info("tool call approval", "server", req.Server, "tool", req.Tool, "approved", decision.Approved, "remember", decision.Remember)
+		return decision, nil
+	}
+}
+
+// editArguments reads replacement arguments as a single line of JSON; an empty line keeps them.
+func editArguments(ctx context.Context, args map[string]any) (map[string]any, bool) {
+	current, _ := json.Marshal(args)
+	fmt.Printf("   current: %s\n", current)
+	line, err := utils.Ask(ctx, "   new arguments (JSON object, empty to keep): ")
+	if err != nil || line == "" {
+		return nil, false
+	}
+	var edited map[string]any
+	if err := json.Unmarshal([]byte(line), &edited); err != nil {
+		fmt.Printf("   invalid JSON: %v\n", err)
+		return nil, false
+	}
+	return edited, true
+}
@@ -0,0 +1,135 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	client_mcp "github.com/pavitra93/11-openai-chats/external/clients/mcp"
+)
+
+// Exit codes of the lint-schemas command
+const (
+	ExitOK       = 0 // every tool schema is accepted
+	ExitProblems = 1 // a tool would be rejected, a server failed to connect, or -strict found incompatible tools
+	ExitUsage    = 2 // bad flags or config file
+)
+
+// serverResult is the lint outcome of one configured server.
+type serverResult struct {
+	Server string                      `json:"server"`
+	Error  string                      `json:"error,omitempty"`
+	Tools  []client_mcp.ToolSchemaLint `json:"tools,omitempty"`
+}
+
+// RunSchemaLint connects to the configured MCP servers, translates every tool schema and reports
+// applied transforms, strict-mode incompatibilities and schemas OpenAI would reject.
+// It returns the process exit code.
+func RunSchemaLint(args []string, defaultConfig string, stdout io.Writer) int {
+	fs := flag.NewFlagSet("lint-schemas", flag.ContinueOnError)
+	configPath := fs.String("mcp-config", defaultConfig, "path to MCP servers config file (YAML or JSON)")
+	strict := fs.Bool("strict", false, "also fail when a tool schema is not strict-mode compatible")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	timeout := fs.Duration("timeout", 30*time.Second, "overall connect timeout")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	servers, err := client_mcp.LoadServerConfigs(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lint-schemas: %v\n", err)
+		return ExitUsage
+	}
+
+	manager := client_mcp.GetManager()
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	results := make([]serverResult, 0, len(servers))
+	failed := false
+	for i := range servers {
+		cfg := &servers[i]
+		if err := manager.RegisterServer(ctx, cfg); err != nil {
+			slog.Error("lint-schemas: failed to connect", "server", cfg.Name, "error", err)
+			results = append(results, serverResult{Server: cfg.Name, Error: err.Error()})
+			failed = true
+			continue
+		}
+		results = append(results, serverResult{Server: cfg.Name})
+	}
+
+	lints := manager.LintToolSchemas()
+	for i := range results {
+		for _, l := range lints {
+			if l.Server != results[i].Server {
+				continue
+			}
+			results[i].Tools = append(results[i].Tools, l)
+			if l.Rejected() || (*strict && len(l.StrictIncompatible) > 0) {
+				failed = true
+			}
+		}
+	}
+
+	for _, r := range results {
+		_ = manager.UnregisterServer(r.Server)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(results)
+	} else {
+		printReport(stdout, results)
+	}
+
+	if failed {
+		return ExitProblems
+	}
+	return ExitOK
+}
+
+// printReport writes a human readable report.
+func printReport(w io.Writer, results []serverResult) {
+	var tools, rejected, nonStrict, transformed int
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "✗ %s: connection failed: %s\n", r.Server, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s (%d tools)\n", r.Server, len(r.Tools))
+		for _, l := range r.Tools {
+			tools++
+			status := "ok"
+			switch {
+			case l.Rejected():
+				status = "REJECTED"
+				rejected++
+			case len(l.StrictIncompatible) > 0:
+				status = "not strict-compatible"
+			}
+			if len(l.StrictIncompatible) > 0 {
+				nonStrict++
+			}
+			if len(l.Transforms) > 0 {
+				transformed++
+			}
+			fmt.Fprintf(w, "  %s [%s]\n", l.Function, status)
+			for _, e := range l.Errors {
+				fmt.Fprintf(w, "    error:     %s\n", e)
+			}
+			for _, s := range l.StrictIncompatible {
+				fmt.Fprintf(w, "    strict:    %s\n", s)
+			}
+			for _, t := range l.Transforms {
+				fmt.Fprintf(w, "    transform: %s\n", t)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d tools: %d rejected, %d not strict-compatible, %d transformed\n", tools, rejected, nonStrict, transformed)
+}
@@ -2,19 +2,24 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/openai/openai-go/v2"
+	anthropic_client "github.com/pavitra93/11-openai-chats/external/clients/anthropic"
+	"github.com/pavitra93/11-openai-chats/external/clients/llm"
 	mcp_client "github.com/pavitra93/11-openai-chats/external/clients/mcp"
 	openai_client "github.com/pavitra93/11-openai-chats/external/clients/openai"
 	send_receive "github.com/pavitra93/11-openai-chats/internal/send-receive"
 	"github.com/pavitra93/11-openai-chats/internal/service/chatbot"
+	"github.com/pavitra93/11-openai-chats/internal/service/lint"
 	"github.com/pavitra93/11-openai-chats/pkg/logger"
 )
 
@@ -22,13 +27,85 @@ func main() {
 	// Load environment variables
 	_ = godotenv.Load()
 
+	// Parse flags
+	defaultMCPConfig := os.Getenv("MCP_CONFIG_FILE")
+	if defaultMCPConfig == "" {
+		defaultMCPConfig = "mcp-servers.yaml"
+	}
+	// Subcommand: lint-schemas checks MCP tool schemas against OpenAI's requirements (for CI)
+	if len(os.Args) > 1 && os.Args[1] == "lint-schemas" {
+		logger.SetupLogger()
+		os.Exit(lint.RunSchemaLint(os.Args[2:], defaultMCPConfig, os.Stdout))
+	}
+
+	mcpConfigPath := flag.String("mcp-config", defaultMCPConfig, "path to MCP servers config file (YAML or JSON)")
+	defaultLLMConfig := os.Getenv("LLM_CONFIG_FILE")
+	if defaultLLMConfig == "" {
+		defaultLLMConfig = "llm-providers.yaml"
+	}
+	llmConfigPath := flag.String("llm-config", defaultLLMConfig, "path to LLM providers config file (YAML or JSON)")
+	flag.Parse()
+
 	// Initialize slog
 	logger.SetupLogger()
 
+	// LLM provider profile: from the providers config file if present, else from environment variables
+	profile, err := loadProviderProfile(*llmConfigPath)
+	if err != nil {
+		slog.Error("Failed to load LLM provider profile", "error", err)
+		os.Exit(1)
+	}
+	model := profile.Model
+
 	// Get environment variables
-	openapiKey := os.Getenv("OPENAI_API_KEY")
-	maxTokens, _ := strconv.ParseInt(os.Getenv("MAX_TOKENS"), 10, 64)
-	temperature, _ := strconv.ParseFloat(os.Getenv("TEMPERATURE"), 64)
+	// generation settings; unset variables keep the API defaults (TEMPERATURE=0 is honored)
+	var generation openai_client.GenerationParams
+	for env, key := range map[string]string{
+		"TEMPERATURE":       "temperature",
+		"TOP_P":             "top_p",
+		"MAX_TOKENS":        "max_tokens",
+		"STOP_SEQUENCES":    "stop",
+		"SEED":              "seed",
+		"PRESENCE_PENALTY":  "presence_penalty",
+		"FREQUENCY_PENALTY": "frequency_penalty",
+		"VERBOSITY":         "verbosity",
+		"REASONING_EFFORT":  "reasoning_effort",
+	} {
+		if err := generation.Set(key, os.Getenv(env)); err != nil {
+			slog.Error("Invalid generation setting", "env", env, "error", err)
+			os.Exit(1)
+		}
+	}
+	maxParallelToolCalls, _ := strconv.Atoi(os.Getenv("MAX_PARALLEL_TOOL_CALLS"))
+
+	// per-turn agent loop budget (zero values use the strategy defaults)
+	maxIterations, _ := strconv.Atoi(os.Getenv("AGENT_MAX_ITERATIONS"))
+	maxToolCalls, _ := strconv.Atoi(os.Getenv("AGENT_MAX_TOOL_CALLS"))
+	maxTurnDuration, _ := time.ParseDuration(os.Getenv("AGENT_MAX_TURN_DURATION"))
+	maxTurnTokens, _ := strconv.ParseInt(os.Getenv("AGENT_MAX_TURN_TOKENS"), 10, 64)
+	maxRepeatedCalls, _ := strconv.Atoi(os.Getenv("AGENT_MAX_REPEATED_CALLS"))
+
+	// parallel_tool_calls is only sent when explicitly configured
+	var parallelToolCalls *bool
+	if v := os.Getenv("OPENAI_PARALLEL_TOOL_CALLS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Error("Invalid OPENAI_PARALLEL_TOOL_CALLS", "value", v, "error", err)
+			os.Exit(1)
+		}
+		parallelToolCalls = &enabled
+	}
+
+	// reasoning summaries are a non-standard extension of some OpenAI-compatible servers; opt-in
+	showReasoning := false
+	if v := os.Getenv("SHOW_REASONING"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Error("Invalid SHOW_REASONING", "value", v, "error", err)
+			os.Exit(1)
+		}
+		showReasoning = enabled
+	}
 
 	// Load system message from file if provided, else from env
 	systemMessage := ""
@@ -43,7 +120,7 @@ func main() {
 		systemMessage = os.Getenv("SYSTEM_MESSAGE")
 	}
 
-	if openapiKey == "" || maxTokens == 0 || temperature == 0 || systemMessage == "" {
+	if systemMessage == "" {
 		slog.Error("Error loading one of environment variables.",
 			slog.Group("error",
 				slog.String("message", "Error loading environment variables."),
@@ -51,40 +128,72 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize OpenAI client & set Config
-	openAIServiceClient := openai_client.GetOpenAIClientInstance(openapiKey)
+	// Initialize the LLM provider & set Config
+	var provider llm.Provider
+	if profile.Type == llm.TypeAnthropic {
+		provider = anthropic_client.NewProviderFromProfile(profile)
+	} else {
+		provider = openai_client.NewProviderFromProfile(profile)
+	}
+	if profile.ToolEmulation {
+		provider = llm.EmulateTools(provider)
+	}
+	slog.Info("LLM provider initialized", "profile", profile.Name, "provider", provider.Name(), "base_url", profile.BaseURL,
+		"model", model, "native_tools", provider.Capabilities().NativeTools, "tool_emulation", profile.ToolEmulation)
 	OpenaiCfg := &openai_client.OpenAIConfig{
-		OpenAPIClient: openAIServiceClient.OpenAIClient,
-		MaxTokens:     maxTokens,
-		Temperature:   temperature,
+		Provider:      provider,
+		Model:         model,
+		Generation:    generation,
 		SystemMessage: systemMessage,
 		History: &openai.ChatCompletionNewParams{
 			Messages: []openai.ChatCompletionMessageParamUnion{
 				openai.SystemMessage(systemMessage),
 			},
 		},
-		AllowHistory: true,
-		HistorySize:  5,
+		AllowHistory:         true,
+		HistorySize:          5,
+		ParallelToolCalls:    parallelToolCalls,
+		MaxParallelToolCalls: maxParallelToolCalls,
+		ShowReasoning:        showReasoning,
+		Budget: openai_client.LoopBudget{
+			MaxIterations:    maxIterations,
+			MaxToolCalls:     maxToolCalls,
+			MaxDuration:      maxTurnDuration,
+			MaxTokens:        maxTurnTokens,
+			MaxRepeatedCalls: maxRepeatedCalls,
+		},
 	}
 
 	// Initialize MCP Clients & set Config
 	mcpManager := mcp_client.GetManager()
+	mcpManager.SetSampler(mcp_client.NewSampler(provider, OpenaiCfg.CurrentModel, chatbot.ApproveSampling))
+
+	// Tool call approval: remembered decisions persist across sessions
+	approvalsPath := os.Getenv("TOOL_APPROVALS_FILE")
+	if approvalsPath == "" {
+		approvalsPath = ".tool-approvals.json"
+		if dir, err := os.UserConfigDir(); err == nil {
+			approvalsPath = filepath.Join(dir, "openai-chatbot", "tool-approvals.json")
+		}
+	}
+	approvals, err := mcp_client.LoadApprovalStore(approvalsPath)
+	if err != nil {
+		slog.Error("Failed to load tool approvals", "path", approvalsPath, "error", err)
+		os.Exit(1)
+	}
+	mcpManager.SetToolApprover(chatbot.ApproveToolCall, approvals)
 	slog.Info("MCP Manager initialized")
 
-	// Build server list and register concurrently with retries
-	servers := []mcp_client.MCPServerConfig{
-		{
-			Name:     os.Getenv("ACCUWEATHER_MCP_NAME"),
-			Endpoint: os.Getenv("ACCUWEATHER_MCP_SERVER_URL"),
-		},
-		{
-			Name:     os.Getenv("NOTION_MCP_NAME"),
-			Endpoint: os.Getenv("NOTION_MCP_SERVER_URL"),
-		},
-		{
-			Name:     os.Getenv("REDIS_MCP_NAME"),
-			Endpoint: os.Getenv("REDIS_MCP_SERVER_URL"),
-		},
+	// Load MCP servers from config file (path via -mcp-config flag or MCP_CONFIG_FILE env)
+	servers := make([]mcp_client.MCPServerConfig, 0)
+	if _, err := os.Stat(*mcpConfigPath); err == nil {
+		servers, err = mcp_client.LoadServerConfigs(*mcpConfigPath)
+		if err != nil {
+			slog.Error("Failed to load MCP config", "path", *mcpConfigPath, "error", err)
+			os.Exit(1)
+		}
+	} else {
+		slog.Warn("MCP config file not found; starting without MCP servers", "path", *mcpConfigPath)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -99,7 +208,54 @@ func main() {
 	SenderStrategy := send_receive.NewSenderRecieverStrategy("once", OpenaiCfg, mcpManager)
 
 	fmt.Println("========Chatbot with Memory=========")
-	MemoryChatbotService := &chatbot.MemoryChatbotService{SenderStrategy: SenderStrategy}
+	MemoryChatbotService := &chatbot.MemoryChatbotService{
+		SenderStrategy: SenderStrategy,
+		MCPManager:     mcpManager,
+		OpenAIConfig:   OpenaiCfg,
+	}
 	MemoryChatbotService.RunMemoryChatbot()
 
 }
+
+// loadProviderProfile selects the LLM provider profile named by LLM_PROVIDER from the config file at path.
+// Without a config file, a single openai or anthropic profile is built from environment variables.
+func loadProviderProfile(path string) (*llm.ProviderProfile, error) {
+	name := os.Getenv("LLM_PROVIDER")
+	if _, err := os.Stat(path); err == nil {
+		return llm.LoadProfile(path, name)
+	}
+
+	if name == "" {
+		name = llm.TypeOpenAI
+	}
+	profile := &llm.ProviderProfile{Name: name, Type: name}
+	switch name {
+	case llm.TypeOpenAI:
+		profile.APIKey = os.Getenv("OPENAI_API_KEY")
+		profile.BaseURL = os.Getenv("OPENAI_BASE_URL")
+		profile.Organization = os.Getenv("OPENAI_ORG_ID")
+		profile.Project = os.Getenv("OPENAI_PROJECT_ID")
+		profile.Model = os.Getenv("OPENAI_MODEL")
+	case llm.TypeAnthropic:
+		profile.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		profile.BaseURL = os.Getenv("ANTHROPIC_BASE_URL")
+		profile.Model = os.Getenv("ANTHROPIC_MODEL")
+	default:
+		return nil, fmt.Errorf("LLM_PROVIDER %q is not openai or anthropic and %s does not exist", name, path)
+	}
+	if v := os.Getenv("LLM_NATIVE_TOOLS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_NATIVE_TOOLS %q: %w", v, err)
+		}
+		profile.NativeTools = &enabled
+	}
+	if v := os.Getenv("LLM_TOOL_EMULATION"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_TOOL_EMULATION %q: %w", v, err)
+		}
+		profile.ToolEmulation = enabled
+	}
+	return profile, profile.Validate()
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// lineResult is one line read from stdin.
+type lineResult struct {
+	line string
+	err  error
+}
+
+var (
+	stdinReader = bufio.NewReader(os.Stdin)
+
+	// readMu serializes readers of stdin and guards pending
+	readMu sync.Mutex
+	// pending delivers the line of a read still in progress. A caller that gives up on its context
+	// leaves the read running; the next caller takes that line instead of starting a competing read.
+	pending chan lineResult
+
+	// askMu serializes interactive questions coming from concurrent goroutines
+	askMu sync.Mutex
+)
+
+// ReadLine returns the next line typed on stdin, or ctx's error if ctx ends first.
+// Every reader of the terminal must go through it, otherwise input is split between readers.
+func ReadLine(ctx context.Context) (string, error) {
+	readMu.Lock()
+	defer readMu.Unlock()
+
+	if pending == nil {
+		ch := make(chan lineResult, 1)
+		pending = ch
+		go func() {
+			line, err := stdinReader.ReadString('\n')
+			ch <- lineResult{line: line, err: err}
+		}()
+	}
+
+	select {
+	case r := <-pending:
+		pending = nil
+		return r.line, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Ask prints question and returns the trimmed line typed by the user.
+// It returns ctx's error if ctx ends before the user answers.
+func Ask(ctx context.Context, question string) (string, error) {
+	askMu.Lock()
+	defer askMu.Unlock()
+
+	fmt.Print(question)
+	line, err := ReadLine(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Println()
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes/no question; anything but y/yes counts as no.
+// It returns ctx's error if ctx ends before the user answers.
+func Confirm(ctx context.Context, question string) (bool, error) {
+	answer, err := Ask(ctx, question+" [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
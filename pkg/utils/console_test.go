@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReadLineCancelKeepsInput(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	stdinReader = bufio.NewReader(pr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := Confirm(ctx, "proceed?"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Confirm error = %v, want deadline exceeded", err)
+	}
+
+	// the abandoned read is still pending; its line goes to the next reader
+	go func() { _, _ = io.WriteString(pw, "next prompt\n") }()
+	line, err := ReadLine(context.Background())
+	if err != nil || line != "next prompt\n" {
+		t.Fatalf("ReadLine = %q, %v; want the line typed after the cancelled question", line, err)
+	}
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPattern matches ${VAR} and ${VAR:-default}
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandEnv replaces ${VAR} / ${VAR:-default} references with values from the environment.
+// Unset or empty variables without a default expand to "" and are reported so validation errors are easier to trace.
+func ExpandEnv(raw string) (string, []string) {
+	missing := make([]string, 0)
+	out := envPattern.ReplaceAllStringFunc(raw, func(match string) string {
+		parts := envPattern.FindStringSubmatch(match)
+		if v, ok := os.LookupEnv(parts[1]); ok && v != "" {
+			return v
+		}
+		if strings.Contains(match, ":-") {
+			return parts[2]
+		}
+		missing = append(missing, parts[1])
+		return ""
+	})
+	return out, missing
+}
+
+// ExpandEnvYAML applies ExpandEnv to every scalar in the YAML tree and returns the missing variables.
+// Only values are interpolated, so comments and keys are left untouched.
+func ExpandEnvYAML(node *yaml.Node) []string {
+	missing := make([]string, 0)
+	if node.Kind == yaml.ScalarNode {
+		expanded, m := ExpandEnv(node.Value)
+		if expanded != node.Value {
+			// let yaml re-resolve the type, e.g. enabled: ${FLAG:-true}
+			node.Value = expanded
+			node.Tag = ""
+		}
+		missing = append(missing, m...)
+	}
+	for i, child := range node.Content {
+		// mapping nodes alternate key and value; keys are not interpolated
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		missing = append(missing, ExpandEnvYAML(child)...)
+	}
+	return missing
+}
@@ -0,0 +1,82 @@
+package utils
+
+import "strings"
+
+// RepairJSON makes a best-effort fix of JSON produced by a model: it strips markdown code fences,
+// drops trailing commas, and closes strings, objects and arrays left open by truncation.
+// The result is not guaranteed to be valid JSON; callers must still parse it.
+func RepairJSON(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "{}"
+	}
+
+	var out strings.Builder
+	stack := make([]byte, 0, 8) // expected closing brackets
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			// a trailing comma before a closing bracket is invalid JSON
+			trimTrailingComma(&out)
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		out.WriteByte(c)
+	}
+
+	repaired := out.String()
+	if inString {
+		// a dangling escape would swallow the closing quote
+		if escaped {
+			repaired = repaired[:len(repaired)-1]
+		}
+		repaired += `"`
+	}
+
+	repaired = strings.TrimRight(repaired, " \t\r\n")
+	repaired = strings.TrimSuffix(repaired, ",")
+	if strings.HasSuffix(repaired, ":") {
+		repaired += "null"
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		repaired += string(stack[i])
+	}
+	return repaired
+}
+
+// trimTrailingComma removes a comma (and following whitespace) at the end of b.
+func trimTrailingComma(b *strings.Builder) {
+	s := b.String()
+	t := strings.TrimRight(s, " \t\r\n")
+	if strings.HasSuffix(t, ",") {
+		b.Reset()
+		b.WriteString(t[:len(t)-1])
+	}
+}
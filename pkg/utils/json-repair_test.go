@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"valid object unchanged", `{"a":1,"b":[true,null]}`, `{"a":1,"b":[true,null]}`},
+		{"empty input", "  ", `{}`},
+		{"markdown fence", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"bare fence", "```\n{\"a\":1}\n```", `{"a":1}`},
+		{"trailing comma in object", `{"a":1,}`, `{"a":1}`},
+		{"trailing comma in array", `{"a":[1,2, ]}`, `{"a":[1,2]}`},
+		{"trailing comma before nested close", `{"a":{"b":1,},}`, `{"a":{"b":1}}`},
+		{"truncated string", `{"query":"hel`, `{"query":"hel"}`},
+		{"truncated string in array", `{"tags":["a","b`, `{"tags":["a","b"]}`},
+		{"dangling escape", `{"path":"C:\`, `{"path":"C:"}`},
+		{"escaped quote kept", `{"q":"say \"hi\"`, `{"q":"say \"hi\""}`},
+		{"truncated after colon", `{"a":1,"b":`, `{"a":1,"b":null}`},
+		{"truncated after comma", `{"a":1, `, `{"a":1}`},
+		{"nested unclosed", `{"a":[1,{"b":2`, `{"a":[1,{"b":2}]}`},
+		{"brackets inside strings", `{"a":"x}],","b":"[{"`, `{"a":"x}],","b":"[{"}`},
+		{"commas inside strings kept", `{"a":"1,}"}`, `{"a":"1,}"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RepairJSON(tt.in)
+			if got != tt.want {
+				t.Fatalf("RepairJSON(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Fatalf("RepairJSON(%q) = %q is not valid JSON", tt.in, got)
+			}
+		})
+	}
+}